@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ilivestrong/rules-engine/rules"
+)
+
+type (
+	AdminResponse struct {
+		Status string `json:"status"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	RulesReloadHandler struct {
+		Products *rules.Products
+	}
+
+	RulesVersionsHandler struct {
+		Products *rules.Products
+	}
+
+	RulesRollbackHandler struct {
+		Products *rules.Products
+	}
+
+	RulesSchemaHandler struct{}
+
+	RollbackRequest struct {
+		Version string `json:"version"`
+	}
+
+	RollbackResponse struct {
+		Status  string               `json:"status"`
+		Version rules.RuleSetVersion `json:"version"`
+	}
+)
+
+func (handler *RulesReloadHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		resp.WriteHeader(http.StatusNotImplemented)
+		fmt.Fprint(resp, "Not implemented")
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	reload := handler.Products.Reload
+	if product := req.URL.Query().Get("product"); product != "" {
+		rulesEngine, ok := productEngine(resp, handler.Products, req)
+		if !ok {
+			return
+		}
+		reload = rulesEngine.Reload
+	}
+	if err := reload(); err != nil {
+		resp.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(resp).Encode(AdminResponse{Status: "rejected", Error: err.Error()})
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(AdminResponse{Status: "reloaded"})
+}
+
+func (handler *RulesVersionsHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		resp.WriteHeader(http.StatusNotImplemented)
+		fmt.Fprint(resp, "Not implemented")
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	rulesEngine, ok := productEngine(resp, handler.Products, req)
+	if !ok {
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(rulesEngine.Versions())
+}
+
+func (handler *RulesRollbackHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		resp.WriteHeader(http.StatusNotImplemented)
+		fmt.Fprint(resp, "Not implemented")
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	rulesEngine, ok := productEngine(resp, handler.Products, req)
+	if !ok {
+		return
+	}
+
+	var rollback RollbackRequest
+	if err := json.NewDecoder(req.Body).Decode(&rollback); err != nil || rollback.Version == "" {
+		resp.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(resp).Encode(AdminResponse{Status: "rejected", Error: "a version id or label is required"})
+		return
+	}
+
+	version, err := rulesEngine.Rollback(rollback.Version)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, rules.ErrUnknownVersion) {
+			status = http.StatusNotFound
+		}
+		resp.WriteHeader(status)
+		json.NewEncoder(resp).Encode(AdminResponse{Status: "rejected", Error: err.Error()})
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(RollbackResponse{Status: "rolled_back", Version: version})
+}
+
+func (handler *RulesSchemaHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		resp.WriteHeader(http.StatusNotImplemented)
+		fmt.Fprint(resp, "Not implemented")
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if kind := req.URL.Query().Get("kind"); kind != "" {
+		schema, ok := rules.ConstraintsJSONSchema(kind)
+		if !ok {
+			resp.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(resp).Encode(AdminResponse{Status: "rejected", Error: fmt.Sprintf("unknown rule kind %q", kind)})
+			return
+		}
+		resp.WriteHeader(http.StatusOK)
+		json.NewEncoder(resp).Encode(schema)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(rules.ConstraintsJSONSchemas())
+}
+
+// productEngine picks the engine of the ?product= query, the default product when it is missing.
+func productEngine(resp http.ResponseWriter, products *rules.Products, req *http.Request) (*rules.RulesEngine, bool) {
+	rulesEngine, _, err := products.Engine(req.URL.Query().Get("product"))
+	if err != nil {
+		resp.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(resp).Encode(AdminResponse{Status: "rejected", Error: err.Error()})
+		return nil, false
+	}
+	return rulesEngine, true
+}
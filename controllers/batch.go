@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/models"
+	"github.com/ilivestrong/rules-engine/rules"
+)
+
+const (
+	// StatusInvalid is the status of batch items that could not be evaluated, see the item's error.
+	StatusInvalid = "invalid"
+
+	DefaultMaxBatchSize = 1000
+)
+
+type (
+	BatchApprovalHandler struct {
+		Products     *rules.Products
+		MaxBatchSize int
+		Workers      int
+		ApprovalTTL  time.Duration
+	}
+
+	BatchItemResult struct {
+		Index        int             `json:"index"`
+		Status       string          `json:"status"`
+		RulesVersion string          `json:"rules_version,omitempty"`
+		Bypassed     bool            `json:"bypassed,omitempty"`
+		Error        string          `json:"error,omitempty"`
+		Decision     *rules.Decision `json:"decision,omitempty"`
+	}
+
+	BatchResponse struct {
+		Product string            `json:"product,omitempty"`
+		Error   string            `json:"error,omitempty"`
+		Results []BatchItemResult `json:"results,omitempty"`
+	}
+)
+
+func (handler *BatchApprovalHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		resp.WriteHeader(http.StatusNotImplemented)
+		fmt.Fprint(resp, "Not implemented")
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	rulesEngine, product, err := handler.Products.Engine(req.URL.Query().Get("product"))
+	if err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(resp).Encode(BatchResponse{Error: err.Error()})
+		return
+	}
+	opts, err := verifyOptions(req)
+	if err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(resp).Encode(BatchResponse{Error: err.Error()})
+		return
+	}
+
+	items, status, err := handler.readItems(req)
+	if err != nil {
+		resp.WriteHeader(status)
+		json.NewEncoder(resp).Encode(BatchResponse{Error: err.Error()})
+		return
+	}
+
+	explain, _ := strconv.ParseBool(req.URL.Query().Get("explain"))
+	results, applicants := handler.evaluate(req.Context(), rulesEngine, items, explain, opts)
+
+	// approved phones are saved together once the workers are done, phones that bypassed the rules keep their entry
+	var approved []helpers.ApprovedPhone
+	for i, result := range results {
+		if result.Status == rules.StatusApproved && !result.Bypassed {
+			approved = append(approved, autoApproved(applicants[i].PhoneNumber, "/process/batch", result.RulesVersion, handler.ApprovalTTL))
+		}
+	}
+	if len(approved) > 0 {
+		if err := rulesEngine.ApprovedPhones().AddIfAbsent(context.Background(), approved...); err != nil {
+			fmt.Printf("failed to save approved phones: %v\n", err)
+		}
+	}
+
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(BatchResponse{Product: product, Results: results})
+}
+
+// readItems reads the JSON array item by item, so an oversized batch is rejected without decoding all of it.
+// Items are kept raw, a malformed item only fails that item.
+func (handler *BatchApprovalHandler) readItems(req *http.Request) ([]json.RawMessage, int, error) {
+	maxBatchSize := handler.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = DefaultMaxBatchSize
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, http.StatusBadRequest, errors.New("request body must be a JSON array of applicants")
+	}
+
+	var items []json.RawMessage
+	for decoder.More() {
+		if len(items) == maxBatchSize {
+			return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("batch exceeds the maximum of %d applicants", maxBatchSize)
+		}
+		var item json.RawMessage
+		if err := decoder.Decode(&item); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid JSON at item %d: %v", len(items), err)
+		}
+		items = append(items, item)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid JSON array: %v", err)
+	}
+	if len(items) == 0 {
+		return nil, http.StatusBadRequest, errors.New("batch has no applicants")
+	}
+	return items, http.StatusOK, nil
+}
+
+// evaluate verifies the items on a bounded pool of workers, results keep the order of the items.
+func (handler *BatchApprovalHandler) evaluate(ctx context.Context, rulesEngine *rules.RulesEngine, items []json.RawMessage, explain bool, opts []rules.VerifyOption) ([]BatchItemResult, []models.Applicant) {
+	workers := handler.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	results := make([]BatchItemResult, len(items))
+	applicants := make([]models.Applicant, len(items))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = evaluateItem(ctx, rulesEngine, i, items[i], &applicants[i], explain, opts)
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, applicants
+}
+
+func evaluateItem(ctx context.Context, rulesEngine *rules.RulesEngine, index int, item json.RawMessage, applicant *models.Applicant, explain bool, opts []rules.VerifyOption) BatchItemResult {
+	result := BatchItemResult{Index: index, Status: StatusInvalid}
+	if err := json.Unmarshal(item, applicant); err != nil {
+		result.Error = fmt.Sprintf("invalid applicant: %v", err)
+		return result
+	}
+	if err := applicant.Validate(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	decision := rulesEngine.Verify(ctx, applicant, opts...)
+	result.Status = decision.Status
+	result.RulesVersion = decision.RulesVersion
+	result.Bypassed = decision.Bypassed
+	if explain {
+		result.Decision = decision
+	}
+	return result
+}
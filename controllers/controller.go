@@ -5,27 +5,70 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ilivestrong/rules-engine/helpers"
 	"github.com/ilivestrong/rules-engine/models"
 	"github.com/ilivestrong/rules-engine/rules"
 )
 
+// processPath is where the handler is mounted, /process/{product} selects a product.
+const processPath = "/process/"
+
 type JSONResponse struct {
-	Status string `json:"status"`
+	Status       string          `json:"status"`
+	Product      string          `json:"product,omitempty"`
+	RulesVersion string          `json:"rules_version,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	Decision     *rules.Decision `json:"decision,omitempty"`
+}
+
+type ProcessRequest struct {
+	models.Applicant
+	Product string `json:"product,omitempty"`
 }
 
 type CrediCardApprovalHandler struct {
-	RulesEngine *rules.RulesEngine
-	FileManager helpers.FileManager
-	DBManager   helpers.RulesEngineRepo
+	Products *rules.Products
+	// ApprovalTTL is how long an approved applicant's phone bypasses the rules, 0 keeps it until removed
+	ApprovalTTL time.Duration
 }
 
 func (handler *CrediCardApprovalHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
-	var applicant models.Applicant
-	err := json.NewDecoder(req.Body).Decode(&applicant)
+	var body ProcessRequest
+	err := json.NewDecoder(req.Body).Decode(&body)
+	applicant := body.Applicant
 
-	if err != nil || !validateInput(&applicant) {
+	if err != nil || applicant.Validate() != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(resp).Encode(JSONResponse{Status: rules.StatusDeclined})
+		return
+	}
+
+	product := body.Product
+	if fromPath := strings.TrimPrefix(req.URL.Path, processPath); fromPath != req.URL.Path && fromPath != "" {
+		if product != "" && product != fromPath {
+			resp.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(resp).Encode(JSONResponse{
+				Status: rules.StatusDeclined,
+				Error:  fmt.Sprintf("product %q in the body does not match %q in the path", product, fromPath),
+			})
+			return
+		}
+		product = fromPath
+	}
+
+	rulesEngine, product, err := handler.Products.Engine(product)
+	if err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(resp).Encode(JSONResponse{Status: rules.StatusDeclined, Error: err.Error()})
+		return
+	}
+
+	opts, err := verifyOptions(req)
+	if err != nil {
 		resp.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(resp).Encode(JSONResponse{Status: rules.StatusDeclined})
 		return
@@ -33,18 +76,19 @@ func (handler *CrediCardApprovalHandler) ServeHTTP(resp http.ResponseWriter, req
 
 	switch req.Method {
 	case http.MethodPost:
-		var response JSONResponse
-		if status := handler.RulesEngine.Verify(req.Context(), &applicant); status != rules.StatusApproved {
-			response = JSONResponse{Status: rules.StatusDeclined}
-		} else {
-			// if err := handler.FileManager.PersistApprovedPhone(applicant.PhoneNumber); err != nil {
-			// 	fmt.Printf("failed to save approved phone")
-			// }
-
-			if err := handler.DBManager.AddApprovedPhone(context.Background(), applicant.PhoneNumber); err != nil {
-				fmt.Printf("failed to save approved phone")
+		decision := rulesEngine.Verify(req.Context(), &applicant, opts...)
+		if decision.Status == rules.StatusApproved && !decision.Bypassed {
+			// saved to the store the Master rule reads, so the phone bypasses the rules from now on. A phone that is
+			// approved already keeps its entry, its expiry and reason.
+			approved := autoApproved(applicant.PhoneNumber, processPath, decision.RulesVersion, handler.ApprovalTTL)
+			if err := rulesEngine.ApprovedPhones().AddIfAbsent(context.Background(), approved); err != nil {
+				fmt.Printf("failed to save approved phone: %v\n", err)
 			}
-			response = JSONResponse{Status: rules.StatusApproved}
+		}
+		response := JSONResponse{Status: decision.Status, Product: product}
+		response.RulesVersion = decision.RulesVersion
+		if explain, _ := strconv.ParseBool(req.URL.Query().Get("explain")); explain {
+			response.Decision = decision
 		}
 		resp.Header().Set("Content-Type", "application/json")
 		resp.WriteHeader(http.StatusOK)
@@ -55,6 +99,28 @@ func (handler *CrediCardApprovalHandler) ServeHTTP(resp http.ResponseWriter, req
 	}
 }
 
-func validateInput(applicant *models.Applicant) bool {
-	return applicant.PoliticallyExposed != nil
+// verifyOptions reads the evaluation options a request can override with its query, e.g. ?mode=evaluate_all.
+func verifyOptions(req *http.Request) ([]rules.VerifyOption, error) {
+	var opts []rules.VerifyOption
+	if mode := req.URL.Query().Get("mode"); mode != "" {
+		if !rules.ValidMode(mode) {
+			return nil, fmt.Errorf("invalid mode %q", mode)
+		}
+		opts = append(opts, rules.WithMode(mode))
+	}
+	return opts, nil
+}
+
+// autoApproved records a phone approved by the rules, addedBy is the endpoint that approved it.
+func autoApproved(phone, addedBy, rulesVersion string, ttl time.Duration) helpers.ApprovedPhone {
+	approved := helpers.ApprovedPhone{Phone: phone, Listing: helpers.Listing{
+		Source:  helpers.SourceAutoApproval,
+		AddedBy: addedBy,
+		Reason:  fmt.Sprintf("approved by rules version %s", rulesVersion),
+	}}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl).UTC()
+		approved.ExpiresAt = &expiresAt
+	}
+	return approved
 }
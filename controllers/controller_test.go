@@ -2,16 +2,25 @@ package controllers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/helpers/mocks"
 	"github.com/ilivestrong/rules-engine/models"
 	"github.com/ilivestrong/rules-engine/rules"
+	"github.com/ilivestrong/rules-engine/stream"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func Test_Process_Handler(t *testing.T) {
@@ -122,11 +131,10 @@ func Test_Process_Handler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fileManager := helpers.NewFileManager()
-			rulesEngine, _ := rules.NewRulesEngine(fileManager)
+			approvedPhones := helpers.NewMemoryApprovedPhoneStore(helpers.ApprovedPhone{Phone: "268-741-8863"})
+			rulesEngine, _ := rules.NewRulesEngine(helpers.NewProductFileManager("../rules"), rules.WithApprovedPhones(approvedPhones))
 			handler := &CrediCardApprovalHandler{
-				RulesEngine: rulesEngine,
-				FileManager: fileManager,
+				Products: rules.SingleProduct(rulesEngine),
 			}
 
 			reqBody, _ := json.Marshal(tt.args.applicant)
@@ -153,7 +161,860 @@ func Test_Process_Handler(t *testing.T) {
 			var got JSONResponse
 			json.Unmarshal(resp, &got)
 
-			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.expected.Status, got.Status)
+			if !tt.expectErr {
+				assert.Equal(t, rulesEngine.Version().ID, got.RulesVersion)
+			}
+		})
+	}
+}
+
+func Test_Process_Handler_Explain(t *testing.T) {
+	PPE := false
+	fileManager := helpers.NewProductFileManager("../rules")
+	rulesEngine, _ := rules.NewRulesEngine(fileManager)
+	handler := &CrediCardApprovalHandler{
+		Products: rules.SingleProduct(rulesEngine),
+	}
+
+	reqBody, _ := json.Marshal(&models.Applicant{
+		Income:              120000,
+		NumberOfCreditCards: 1,
+		Age:                 10,
+		PoliticallyExposed:  &PPE,
+		JobIndustryCode:     "15-100 - Plumbing",
+		PhoneNumber:         "269-741-8863",
+	})
+	req, err := http.NewRequest(http.MethodPost, "/process?explain=true", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	var got JSONResponse
+	json.Unmarshal(rr.Body.Bytes(), &got)
+
+	assert.Equal(t, rules.StatusDeclined, got.Status)
+	if assert.NotNil(t, got.Decision) {
+		assert.Equal(t, rules.StatusDeclined, got.Decision.Status)
+		assert.False(t, got.Decision.Bypassed)
+		assert.False(t, got.Decision.Denied)
+		if assert.Greater(t, len(got.Decision.Rules), 1) {
+			assert.Equal(t, rules.RuleDenyList, got.Decision.Rules[0].Name)
+			assert.Equal(t, rules.RuleMaster, got.Decision.Rules[1].Name)
+		}
+	}
+}
+
+func Test_Process_Handler_InvalidMode(t *testing.T) {
+	PPE := false
+	fileManager := helpers.NewProductFileManager("../rules")
+	rulesEngine, _ := rules.NewRulesEngine(fileManager)
+	handler := &CrediCardApprovalHandler{
+		Products: rules.SingleProduct(rulesEngine),
+	}
+
+	reqBody, _ := json.Marshal(&models.Applicant{PoliticallyExposed: &PPE})
+	req, err := http.NewRequest(http.MethodPost, "/process?mode=sometimes", bytes.NewBuffer(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func Test_Process_Handler_Products(t *testing.T) {
+	PPE := false
+	engines := map[string]*rules.RulesEngine{}
+	standard, _ := rules.NewRulesEngine(helpers.NewProductFileManager("../rules"))
+	engines[rules.DefaultProduct] = standard
+	dirs, _ := helpers.ProductDirs("../rules")
+	for product, dir := range dirs {
+		engines[product], _ = rules.NewRulesEngine(helpers.NewProductFileManager(dir))
+	}
+	products, err := rules.NewProducts(rules.DefaultProduct, engines)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &CrediCardApprovalHandler{Products: products}
+
+	applicant := models.Applicant{
+		Income:              120000,
+		NumberOfCreditCards: 1,
+		Age:                 29,
+		PoliticallyExposed:  &PPE,
+		JobIndustryCode:     "15-100 - Plumbing",
+		PhoneNumber:         "269-741-8863",
+	}
+
+	tests := []struct {
+		name            string
+		path            string
+		product         string
+		expectedCode    int
+		expectedStatus  string
+		expectedProduct string
+	}{
+		{
+			name:            "default product",
+			path:            "/process",
+			expectedCode:    http.StatusOK,
+			expectedStatus:  rules.StatusApproved,
+			expectedProduct: rules.DefaultProduct,
+		},
+		{
+			name:            "product in body",
+			path:            "/process",
+			product:         "premium",
+			expectedCode:    http.StatusOK,
+			expectedStatus:  rules.StatusDeclined,
+			expectedProduct: "premium",
+		},
+		{
+			name:            "product in path",
+			path:            "/process/premium",
+			expectedCode:    http.StatusOK,
+			expectedStatus:  rules.StatusDeclined,
+			expectedProduct: "premium",
+		},
+		{
+			name:            "same product in path and body",
+			path:            "/process/secured",
+			product:         "secured",
+			expectedCode:    http.StatusOK,
+			expectedStatus:  rules.StatusApproved,
+			expectedProduct: "secured",
+		},
+		{
+			name:           "path and body disagree",
+			path:           "/process/secured",
+			product:        "premium",
+			expectedCode:   http.StatusBadRequest,
+			expectedStatus: rules.StatusDeclined,
+		},
+		{
+			name:           "unknown product",
+			path:           "/process/gold",
+			expectedCode:   http.StatusBadRequest,
+			expectedStatus: rules.StatusDeclined,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody, _ := json.Marshal(ProcessRequest{Applicant: applicant, Product: tt.product})
+			req, err := http.NewRequest(http.MethodPost, tt.path, bytes.NewBuffer(reqBody))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			var got JSONResponse
+			json.Unmarshal(rr.Body.Bytes(), &got)
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedStatus, got.Status)
+			assert.Equal(t, tt.expectedProduct, got.Product)
+			if tt.expectedCode != http.StatusOK {
+				assert.NotEmpty(t, got.Error)
+			}
+		})
+	}
+}
+
+func Test_RulesReloadHandler(t *testing.T) {
+	fileManager := helpers.NewProductFileManager("../rules")
+	rulesEngine, _ := rules.NewRulesEngine(fileManager)
+	handler := &RulesReloadHandler{Products: rules.SingleProduct(rulesEngine)}
+
+	req, err := http.NewRequest(http.MethodPost, "/admin/reload", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	var got AdminResponse
+	json.Unmarshal(rr.Body.Bytes(), &got)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, AdminResponse{Status: "reloaded"}, got)
+}
+
+func Test_RulesRollbackHandler(t *testing.T) {
+	fileManager := helpers.NewProductFileManager("../rules")
+	rulesEngine, _ := rules.NewRulesEngine(fileManager)
+	handler := &RulesRollbackHandler{Products: rules.SingleProduct(rulesEngine)}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "missing version",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown version",
+			body:           `{"version": "does-not-exist"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "known version",
+			body:           `{"version": "` + rulesEngine.Version().ID + `"}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/admin/rules/rollback", bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func Test_RulesSchemaHandler(t *testing.T) {
+	handler := &RulesSchemaHandler{}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{
+			name:           "all kinds",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "single kind",
+			query:          "?kind=Age",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown kind",
+			query:          "?kind=FraudCheck",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/admin/rules/schema"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
 		})
 	}
 }
+
+func Test_BatchApprovalHandler(t *testing.T) {
+	approvedPhones := helpers.NewMemoryApprovedPhoneStore()
+	rulesEngine, _ := rules.NewRulesEngine(helpers.NewProductFileManager("../rules"), rules.WithApprovedPhones(approvedPhones))
+	handler := &BatchApprovalHandler{
+		Products:     rules.SingleProduct(rulesEngine),
+		MaxBatchSize: 3,
+		Workers:      2,
+	}
+
+	tests := []struct {
+		name            string
+		body            string
+		expectedCode    int
+		expectedResults []BatchItemResult
+	}{
+		{
+			name: "per item decisions in order",
+			body: `[
+				{"income": 120000, "number_of_credit_cards": 1, "age": 29, "politically_exposed": false, "phone_number": "269-741-8863"},
+				{"income": 120000, "number_of_credit_cards": 1, "age": 10, "politically_exposed": false, "phone_number": "269-741-8864"},
+				{"income": "a lot", "politically_exposed": false}
+			]`,
+			expectedCode: http.StatusOK,
+			expectedResults: []BatchItemResult{
+				{Index: 0, Status: rules.StatusApproved, RulesVersion: rulesEngine.Version().ID},
+				{Index: 1, Status: rules.StatusDeclined, RulesVersion: rulesEngine.Version().ID},
+				{Index: 2, Status: StatusInvalid, Error: "invalid applicant: json: cannot unmarshal string into Go struct field Applicant.income of type int"},
+			},
+		},
+		{
+			name:         "validation errors stay per item",
+			body:         `[{"income": 120000}]`,
+			expectedCode: http.StatusOK,
+			expectedResults: []BatchItemResult{
+				{Index: 0, Status: StatusInvalid, Error: "politically_exposed is required"},
+			},
+		},
+		{
+			name:         "batch too large",
+			body:         `[{}, {}, {}, {}]`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "not an array",
+			body:         `{"income": 120000}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "empty batch",
+			body:         `[]`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/process/batch", bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			var got BatchResponse
+			json.Unmarshal(rr.Body.Bytes(), &got)
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedResults, got.Results)
+			if tt.expectedCode != http.StatusOK {
+				assert.NotEmpty(t, got.Error)
+			}
+		})
+	}
+
+	phones, total, _ := approvedPhones.List(context.Background(), 0, 0)
+	if assert.Equal(t, 1, total) {
+		assert.Equal(t, "+12697418863", phones[0].Phone)
+		assert.Equal(t, helpers.SourceAutoApproval, phones[0].Source)
+		assert.Equal(t, "/process/batch", phones[0].AddedBy)
+		assert.Equal(t, "approved by rules version "+rulesEngine.Version().ID, phones[0].Reason)
+		assert.NotNil(t, phones[0].AddedAt)
+	}
+}
+
+func Test_Process_Handler_ProductApprovals(t *testing.T) {
+	PPE := false
+	standard, _ := rules.NewRulesEngine(helpers.NewProductFileManager("../rules"), rules.WithApprovedPhones(helpers.NewMemoryApprovedPhoneStore()))
+	premium, _ := rules.NewRulesEngine(helpers.NewProductFileManager("../rules/products/premium"), rules.WithApprovedPhones(helpers.NewMemoryApprovedPhoneStore()))
+	products, err := rules.NewProducts(rules.DefaultProduct, map[string]*rules.RulesEngine{rules.DefaultProduct: standard, "premium": premium})
+	if err != nil {
+		t.Fatal(err)
+	}
+	phones := &ApprovedPhonesHandler{Products: products}
+	process := &CrediCardApprovalHandler{Products: products}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/approved-phones?product="+rules.DefaultProduct, strings.NewReader(`{"phone": "269-741-8863"}`))
+	rr := httptest.NewRecorder()
+	phones.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	// premium declines this applicant, the phone approved for the standard product must not bypass its rules
+	body, _ := json.Marshal(ProcessRequest{
+		Applicant: models.Applicant{Income: 120000, NumberOfCreditCards: 1, Age: 29, PoliticallyExposed: &PPE, PhoneNumber: "269-741-8863"},
+	})
+	for product, expected := range map[string]bool{rules.DefaultProduct: true, "premium": false} {
+		rr := httptest.NewRecorder()
+		process.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/process/"+product+"?explain=true", bytes.NewReader(body)))
+
+		var got JSONResponse
+		if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, got.Decision.Bypassed, product)
+		if !expected {
+			assert.Equal(t, rules.StatusDeclined, got.Status)
+			_, err := premium.ApprovedPhones().Get(context.Background(), "269-741-8863")
+			assert.ErrorIs(t, err, helpers.ErrPhoneNotFound)
+		}
+	}
+}
+
+func Test_Process_Handler_KeepsApprovedPhones(t *testing.T) {
+	PPE := false
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	vip := helpers.ApprovedPhone{Phone: "+12697418863", Listing: helpers.Listing{Source: helpers.SourceAdmin, AddedBy: "ops", ExpiresAt: &expiresAt, Reason: "vip"}}
+	applicant := models.Applicant{Income: 120000, NumberOfCreditCards: 1, Age: 29, PoliticallyExposed: &PPE, PhoneNumber: "269-741-8863"}
+	process := func(t *testing.T, handler http.Handler, path, body string) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+	body, _ := json.Marshal(applicant)
+	batch, _ := json.Marshal([]models.Applicant{applicant, {Income: 120000, NumberOfCreditCards: 1, Age: 29, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507"}})
+
+	t.Run("bypassed applicants are not saved again", func(t *testing.T) {
+		phonesList := filepath.Join(t.TempDir(), "approved-phone-list.json")
+		if err := ioutil.WriteFile(phonesList, []byte(`{}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		store := helpers.NewFileApprovedPhoneStore(phonesList)
+		assert.NoError(t, store.Add(context.Background(), vip))
+		rulesEngine, _ := rules.NewRulesEngine(helpers.NewProductFileManager("../rules"), rules.WithApprovedPhones(store))
+		products := rules.SingleProduct(rulesEngine)
+
+		process(t, &CrediCardApprovalHandler{Products: products}, "/process", string(body))
+		process(t, &BatchApprovalHandler{Products: products}, "/process/batch", `[`+string(body)+`]`)
+
+		approved, err := store.Get(context.Background(), vip.Phone)
+		if assert.NoError(t, err) {
+			vip.AddedAt = approved.AddedAt
+			assert.Equal(t, vip, *approved)
+		}
+	})
+
+	t.Run("approved phones keep their entry", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+			RequiredRules: []string{},
+			Rules: []models.RuleInfo{
+				{Name: rules.RuleMaster, Constraints: map[string]any{"check_approved_phones": false}},
+				{Name: rules.RuleIncome, Constraints: map[string]any{"minimum_salary": 100000}},
+			},
+		}, nil)
+		store := helpers.NewMemoryApprovedPhoneStore(vip)
+		rulesEngine, _ := rules.NewRulesEngine(fileManager, rules.WithApprovedPhones(store))
+		products := rules.SingleProduct(rulesEngine)
+
+		process(t, &CrediCardApprovalHandler{Products: products, ApprovalTTL: 24 * time.Hour}, "/process", string(body))
+		process(t, &BatchApprovalHandler{Products: products}, "/process/batch", string(batch))
+
+		approved, err := store.Get(context.Background(), vip.Phone)
+		if assert.NoError(t, err) {
+			assert.Equal(t, vip, *approved)
+		}
+		added, err := store.Get(context.Background(), "202-324-0507")
+		if assert.NoError(t, err) {
+			assert.Equal(t, helpers.SourceAutoApproval, added.Source)
+		}
+	})
+}
+
+func Test_StreamApprovalHandler(t *testing.T) {
+	fileManager := helpers.NewProductFileManager("../rules")
+	rulesEngine, _ := rules.NewRulesEngine(fileManager)
+	handler := &StreamApprovalHandler{Products: rules.SingleProduct(rulesEngine), Workers: 2}
+
+	tests := []struct {
+		name            string
+		query           string
+		body            string
+		expectedCode    int
+		expectedResults []stream.Result
+	}{
+		{
+			name: "one decision per line",
+			body: `{"income": 120000, "number_of_credit_cards": 1, "age": 29, "politically_exposed": false, "phone_number": "269-741-8863"}
+{"income": 120000, "number_of_credit_cards": 1, "age": 10, "politically_exposed": false, "phone_number": "269-741-8863"}
+not json
+`,
+			expectedCode: http.StatusOK,
+			expectedResults: []stream.Result{
+				{Line: 1, Status: rules.StatusApproved, RulesVersion: rulesEngine.Version().ID},
+				{Line: 2, Status: rules.StatusDeclined, RulesVersion: rulesEngine.Version().ID},
+				{Line: 3, Status: stream.StatusInvalid, Error: "invalid applicant: invalid character 'o' in literal null (expecting 'u')"},
+			},
+		},
+		{
+			name:         "unknown product",
+			query:        "?product=gold",
+			expectedCode: http.StatusBadRequest,
+			expectedResults: []stream.Result{
+				{Status: stream.StatusInvalid, Error: `unknown product "gold", expected one of standard`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/process/stream"+tt.query, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			var got []stream.Result
+			decoder := json.NewDecoder(rr.Body)
+			for decoder.More() {
+				var res stream.Result
+				if err := decoder.Decode(&res); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, res)
+			}
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedResults, got)
+		})
+	}
+}
+
+func Test_StreamApprovalHandler_HTTP1(t *testing.T) {
+	fileManager := helpers.NewProductFileManager("../rules")
+	rulesEngine, _ := rules.NewRulesEngine(fileManager)
+	server := httptest.NewUnstartedServer(&StreamApprovalHandler{Products: rules.SingleProduct(rulesEngine), Workers: 2, Timeout: 300 * time.Millisecond})
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	applicant := `{"income": 120000, "number_of_credit_cards": 1, "age": 29, "politically_exposed": false, "phone_number": "269-741-8863"}` + "\n"
+	body, requests := io.Pipe()
+	go requests.Write([]byte(applicant))
+
+	resp, err := http.Post(server.URL, "application/x-ndjson", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, 1, resp.ProtoMajor)
+	decoder := json.NewDecoder(resp.Body)
+
+	// the first decision arrives while the request is still open
+	var first stream.Result
+	assert.NoError(t, decoder.Decode(&first))
+	assert.Equal(t, stream.Result{Line: 1, Status: rules.StatusApproved, RulesVersion: rulesEngine.Version().ID}, first)
+
+	// and the stream outlives the server timeouts
+	time.Sleep(200 * time.Millisecond)
+	requests.Write([]byte(applicant))
+	requests.Close()
+
+	var second stream.Result
+	assert.NoError(t, decoder.Decode(&second))
+	assert.Equal(t, 2, second.Line)
+	assert.False(t, decoder.More())
+}
+
+func Test_StreamApprovalHandler_IdleClient(t *testing.T) {
+	fileManager := helpers.NewProductFileManager("../rules")
+	rulesEngine, _ := rules.NewRulesEngine(fileManager)
+	server := httptest.NewServer(&StreamApprovalHandler{Products: rules.SingleProduct(rulesEngine), Workers: 2, Timeout: 100 * time.Millisecond})
+	defer server.Close()
+
+	applicant := `{"income": 120000, "number_of_credit_cards": 1, "age": 29, "politically_exposed": false, "phone_number": "269-741-8863"}` + "\n"
+	body, requests := io.Pipe()
+	defer requests.Close()
+	go requests.Write([]byte(applicant))
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(server.URL, "application/x-ndjson", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(resp.Body)
+
+	var first stream.Result
+	assert.NoError(t, decoder.Decode(&first))
+	assert.Equal(t, 1, first.Line)
+
+	// a client that stops sending is dropped instead of holding the stream open
+	var stopped stream.Result
+	assert.NoError(t, decoder.Decode(&stopped))
+	assert.Equal(t, 2, stopped.Line)
+	assert.Equal(t, stream.StatusInvalid, stopped.Status)
+	assert.Contains(t, stopped.Error, "i/o timeout")
+	assert.False(t, decoder.More())
+}
+
+func Test_ApprovedPhonesHandler(t *testing.T) {
+	phonesList := filepath.Join(t.TempDir(), "approved-phone-list.json")
+	if err := ioutil.WriteFile(phonesList, []byte(`{"268-741-8863":true,"111-111-1111":{"expires_at":"2001-01-01T00:00:00Z"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	approvedPhones := helpers.NewFileApprovedPhoneStore(phonesList)
+	rulesEngine, _ := rules.NewRulesEngine(helpers.NewProductFileManager("../rules"), rules.WithApprovedPhones(approvedPhones))
+	handler := &ApprovedPhonesHandler{
+		Products: rules.SingleProduct(rulesEngine),
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "check approved phone",
+			method:         http.MethodGet,
+			path:           "/admin/approved-phones/268-741-8863",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"phone":"+12687418863"`,
+		},
+		{
+			name:           "check approved phone written differently",
+			method:         http.MethodGet,
+			path:           "/admin/approved-phones/(268)%20741%208863",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"phone":"+12687418863"`,
+		},
+		{
+			name:           "expired phone is not approved",
+			method:         http.MethodGet,
+			path:           "/admin/approved-phones/111-111-1111",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "add phone with ttl",
+			method:         http.MethodPost,
+			path:           "/admin/approved-phones",
+			body:           `{"phone": "222-222-2222", "ttl": "24h", "reason": "VIP customer"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"expires_at"`,
+		},
+		{
+			name:           "add invalid phone",
+			method:         http.MethodPost,
+			path:           "/admin/approved-phones",
+			body:           `{"phone": "222-2222"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `must have 10 digits`,
+		},
+		{
+			name:           "add phone expiring in the past",
+			method:         http.MethodPost,
+			path:           "/admin/approved-phones",
+			body:           `{"phone": "222-222-2222", "expires_at": "2001-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "import is all or nothing",
+			method:         http.MethodPost,
+			path:           "/admin/approved-phones/import",
+			body:           `[{"phone": "333-333-3333"}, {"phone": ""}]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `item 1: phone is required`,
+		},
+		{
+			name:           "import phones",
+			method:         http.MethodPost,
+			path:           "/admin/approved-phones/import",
+			body:           `[{"phone": "333-333-3333", "added_by": "risk-team"}, {"phone": "444-444-4444"}]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"imported":2`,
+		},
+		{
+			name:           "list second page",
+			method:         http.MethodGet,
+			path:           "/admin/approved-phones?offset=1&limit=2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"total":4,"offset":1,"limit":2,"phones":[{"phone":"+12687418863"},{"phone":"+13333333333","source":"import","added_by":"risk-team"`,
+		},
+		{
+			name:           "invalid limit",
+			method:         http.MethodGet,
+			path:           "/admin/approved-phones?limit=-1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "remove phone",
+			method:         http.MethodDelete,
+			path:           "/admin/approved-phones/+1-333-333-3333",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "remove unknown phone",
+			method:         http.MethodDelete,
+			path:           "/admin/approved-phones/333-333-3333",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unknown product",
+			method:         http.MethodGet,
+			path:           "/admin/approved-phones?product=gold",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unsupported method",
+			method:         http.MethodPut,
+			path:           "/admin/approved-phones",
+			expectedStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+
+	t.Run("store failure", func(t *testing.T) {
+		store := mocks.NewApprovedPhoneStore(t)
+		store.On("Get", mock.Anything, "268-741-8863").Return(nil, errors.New("connection refused"))
+		rulesEngine, _ := rules.NewRulesEngine(helpers.NewProductFileManager("../rules"), rules.WithApprovedPhones(store))
+		handler := &ApprovedPhonesHandler{
+			Products: rules.SingleProduct(rulesEngine),
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/admin/approved-phones/268-741-8863", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func Test_DenyListHandler(t *testing.T) {
+	denyList := helpers.NewFileDenyListStore(filepath.Join(t.TempDir(), "deny-list.json"))
+	rulesEngine, _ := rules.NewRulesEngine(helpers.NewProductFileManager("../rules"), rules.WithDenyList(denyList))
+	handler := &DenyListHandler{
+		Products: rules.SingleProduct(rulesEngine),
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "empty deny list",
+			method:         http.MethodGet,
+			path:           "/admin/deny-list",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"total":0`,
+		},
+		{
+			name:           "deny phone",
+			method:         http.MethodPost,
+			path:           "/admin/deny-list",
+			body:           `{"field": "phone_number", "value": "269-741-8863", "reason": "confirmed fraud"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"source":"admin"`,
+		},
+		{
+			name:           "deny unknown field",
+			method:         http.MethodPost,
+			path:           "/admin/deny-list",
+			body:           `{"field": "email", "value": "a@b.c"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `unknown applicant field`,
+		},
+		{
+			name:           "import is all or nothing",
+			method:         http.MethodPost,
+			path:           "/admin/deny-list/import",
+			body:           `[{"field": "job_industry_code", "value": "2-930"}, {"field": "job_industry_code"}]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `item 1: value is required`,
+		},
+		{
+			name:           "import values",
+			method:         http.MethodPost,
+			path:           "/admin/deny-list/import",
+			body:           `[{"field": "job_industry_code", "value": "2-930"}, {"field": "phone_number", "value": "111-111-1111", "ttl": "1h"}]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"imported":2`,
+		},
+		{
+			name:           "check denied value",
+			method:         http.MethodGet,
+			path:           "/admin/deny-list/phone_number/269-741-8863",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"reason":"confirmed fraud"`,
+		},
+		{
+			name:           "list first page",
+			method:         http.MethodGet,
+			path:           "/admin/deny-list?limit=1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"total":3,"offset":0,"limit":1,"denied":[{"field":"job_industry_code","value":"2-930","source":"import"`,
+		},
+		{
+			name:           "remove value",
+			method:         http.MethodDelete,
+			path:           "/admin/deny-list/job_industry_code/2-930",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "remove unknown value",
+			method:         http.MethodDelete,
+			path:           "/admin/deny-list/job_industry_code/2-930",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unsupported method",
+			method:         http.MethodPut,
+			path:           "/admin/deny-list",
+			expectedStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+
+	t.Run("denied applicants are declined", func(t *testing.T) {
+		PPE := false
+		processHandler := &CrediCardApprovalHandler{
+			Products: rules.SingleProduct(rulesEngine),
+		}
+		reqBody, _ := json.Marshal(&models.Applicant{
+			Income:              120000,
+			NumberOfCreditCards: 1,
+			Age:                 30,
+			PoliticallyExposed:  &PPE,
+			JobIndustryCode:     "15-100 - Plumbing",
+			PhoneNumber:         "269-741-8863",
+		})
+		req, _ := http.NewRequest(http.MethodPost, "/process?explain=true", bytes.NewBuffer(reqBody))
+		rr := httptest.NewRecorder()
+
+		processHandler.ServeHTTP(rr, req)
+
+		var got JSONResponse
+		json.Unmarshal(rr.Body.Bytes(), &got)
+		assert.Equal(t, rules.StatusDeclined, got.Status)
+		if assert.NotNil(t, got.Decision) {
+			assert.True(t, got.Decision.Denied)
+			assert.Equal(t, "confirmed fraud", got.Decision.DenyReason)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/phone"
+	"github.com/ilivestrong/rules-engine/rules"
+)
+
+const (
+	// denyListPath is where the handler is mounted, /admin/deny-list/{field}/{value} addresses one value.
+	denyListPath = "/admin/deny-list/"
+
+	DefaultDenyListPageSize = 100
+	MaxDenyListPageSize     = 1000
+	MaxDenyListImport       = 10000
+)
+
+type (
+	// DenyListHandler manages the deny list of each product at runtime.
+	DenyListHandler struct {
+		Products *rules.Products
+	}
+
+	// DeniedValueRequest denies an applicant value.
+	DeniedValueRequest struct {
+		Field string `json:"field"`
+		Value string `json:"value"`
+		ListingRequest
+	}
+
+	DeniedValueResponse struct {
+		Status   string               `json:"status"`
+		Product  string               `json:"product,omitempty"`
+		Denied   *helpers.DeniedValue `json:"denied,omitempty"`
+		Imported int                  `json:"imported,omitempty"`
+	}
+
+	DenyListPage struct {
+		Product string                `json:"product"`
+		Total   int                   `json:"total"`
+		Offset  int                   `json:"offset"`
+		Limit   int                   `json:"limit"`
+		Denied  []helpers.DeniedValue `json:"denied"`
+	}
+)
+
+func (handler *DenyListHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Set("Content-Type", "application/json")
+	rulesEngine, product, err := handler.Products.Engine(req.URL.Query().Get("product"))
+	if err != nil {
+		rejectAdmin(resp, http.StatusNotFound, err)
+		return
+	}
+	// the product's rules engine checks the same store, so changes apply to the next application
+	store := rulesEngine.DenyList()
+
+	field, value := "", ""
+	if rest := strings.TrimPrefix(req.URL.Path, denyListPath); rest != req.URL.Path {
+		field, value, _ = strings.Cut(strings.Trim(rest, "/"), "/")
+	}
+
+	switch {
+	case field == "" && req.Method == http.MethodGet:
+		handler.list(resp, req, store, product)
+	case field == "" && req.Method == http.MethodPost:
+		handler.add(resp, req, store, product)
+	case field == "import" && value == "" && req.Method == http.MethodPost:
+		handler.bulkImport(resp, req, store, product)
+	case value != "" && req.Method == http.MethodGet:
+		handler.check(resp, req, store, product, field, value)
+	case value != "" && req.Method == http.MethodDelete:
+		handler.remove(resp, req, store, product, field, value)
+	default:
+		resp.WriteHeader(http.StatusNotImplemented)
+		fmt.Fprint(resp, "Not implemented")
+	}
+}
+
+func (handler *DenyListHandler) list(resp http.ResponseWriter, req *http.Request, store helpers.DenyListStore, product string) {
+	offset, limit, err := pageParams(req, DefaultDenyListPageSize, MaxDenyListPageSize)
+	if err != nil {
+		rejectAdmin(resp, http.StatusBadRequest, err)
+		return
+	}
+
+	denied, total, err := store.List(req.Context(), offset, limit)
+	if err != nil {
+		rejectAdmin(resp, http.StatusInternalServerError, err)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(DenyListPage{Product: product, Total: total, Offset: offset, Limit: limit, Denied: denied})
+}
+
+func (handler *DenyListHandler) add(resp http.ResponseWriter, req *http.Request, store helpers.DenyListStore, product string) {
+	var body DeniedValueRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		rejectAdmin(resp, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		return
+	}
+	denied, err := body.deniedValue(helpers.SourceAdmin, time.Now())
+	if err != nil {
+		rejectAdmin(resp, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := store.Add(req.Context(), denied); err != nil {
+		rejectAdmin(resp, http.StatusInternalServerError, err)
+		return
+	}
+	resp.WriteHeader(http.StatusCreated)
+	json.NewEncoder(resp).Encode(DeniedValueResponse{Status: "denied", Product: product, Denied: &denied})
+}
+
+// bulkImport denies a JSON array of values. Nothing is saved unless every value is valid.
+func (handler *DenyListHandler) bulkImport(resp http.ResponseWriter, req *http.Request, store helpers.DenyListStore, product string) {
+	body, status, err := decodeImport[DeniedValueRequest](req, MaxDenyListImport, "values")
+	if err != nil {
+		rejectAdmin(resp, status, err)
+		return
+	}
+
+	now := time.Now()
+	values := make([]helpers.DeniedValue, len(body))
+	for i, item := range body {
+		denied, err := item.deniedValue(helpers.SourceImport, now)
+		if err != nil {
+			rejectAdmin(resp, http.StatusBadRequest, fmt.Errorf("item %d: %v", i, err))
+			return
+		}
+		values[i] = denied
+	}
+
+	if err := store.Add(req.Context(), values...); err != nil {
+		rejectAdmin(resp, http.StatusInternalServerError, err)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(DeniedValueResponse{Status: "imported", Product: product, Imported: len(values)})
+}
+
+func (handler *DenyListHandler) check(resp http.ResponseWriter, req *http.Request, store helpers.DenyListStore, product, field, value string) {
+	denied, err := store.Get(req.Context(), field, value)
+	if err != nil {
+		rejectStore(resp, err, helpers.ErrNotDenied)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(DeniedValueResponse{Status: "denied", Product: product, Denied: denied})
+}
+
+func (handler *DenyListHandler) remove(resp http.ResponseWriter, req *http.Request, store helpers.DenyListStore, product, field, value string) {
+	err := store.Remove(req.Context(), field, value)
+	if err != nil {
+		rejectStore(resp, err, helpers.ErrNotDenied)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(DeniedValueResponse{Status: "removed", Product: product, Denied: &helpers.DeniedValue{Field: field, Value: value}})
+}
+
+// deniedValue checks the request, phone numbers are kept in their canonical form.
+func (dvr DeniedValueRequest) deniedValue(source string, now time.Time) (helpers.DeniedValue, error) {
+	denied := helpers.DeniedValue{Field: strings.TrimSpace(dvr.Field), Value: strings.TrimSpace(dvr.Value)}
+	if !rules.ApplicantField(denied.Field) {
+		return denied, fmt.Errorf("unknown applicant field %q", denied.Field)
+	}
+	if denied.Value == "" {
+		return denied, errors.New("value is required")
+	}
+	if denied.Field == "phone_number" {
+		number, err := phone.Parse(denied.Value, phone.FirstDigit)
+		if err != nil {
+			return denied, err
+		}
+		denied.Value = number.Canonical()
+	}
+	listing, err := dvr.listing(source, now)
+	denied.Listing = listing
+	return denied, err
+}
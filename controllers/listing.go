@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/helpers"
+)
+
+// ListingRequest is who lists a value and why, optionally until expires_at or for a ttl such as "720h".
+type ListingRequest struct {
+	AddedBy   string     `json:"added_by,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	TTL       string     `json:"ttl,omitempty"`
+}
+
+// listing checks the request and works out its expiry, a ttl counts from now.
+func (lr ListingRequest) listing(source string, now time.Time) (helpers.Listing, error) {
+	addedAt := now.UTC()
+	listing := helpers.Listing{
+		Source:    source,
+		AddedBy:   lr.AddedBy,
+		AddedAt:   &addedAt,
+		ExpiresAt: lr.ExpiresAt,
+		Reason:    lr.Reason,
+	}
+	if lr.TTL != "" {
+		if lr.ExpiresAt != nil {
+			return listing, errors.New("set either expires_at or ttl, not both")
+		}
+		ttl, err := time.ParseDuration(lr.TTL)
+		if err != nil || ttl <= 0 {
+			return listing, fmt.Errorf("invalid ttl %q", lr.TTL)
+		}
+		expiresAt := now.Add(ttl).UTC()
+		listing.ExpiresAt = &expiresAt
+	}
+	if listing.Expired(now) {
+		return listing, errors.New("expires_at is in the past")
+	}
+	return listing, nil
+}
+
+// pageParams reads the offset and limit of a list page, the limit is capped at maxSize.
+func pageParams(req *http.Request, defaultSize, maxSize int) (int, int, error) {
+	offset, err := queryInt(req, "offset", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	limit, err := queryInt(req, "limit", defaultSize)
+	if err != nil || limit == 0 {
+		return 0, 0, errors.New("limit must be a positive number")
+	}
+	if limit > maxSize {
+		limit = maxSize
+	}
+	return offset, limit, nil
+}
+
+// decodeImport reads a JSON array of up to max items, what names the items in errors, e.g. "phones".
+func decodeImport[T any](req *http.Request, max int, what string) ([]T, int, error) {
+	var items []T
+	if err := json.NewDecoder(req.Body).Decode(&items); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("request body must be a JSON array of %s", what)
+	}
+	if len(items) == 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("import has no %s", what)
+	}
+	if len(items) > max {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("import exceeds the maximum of %d %s", max, what)
+	}
+	return items, http.StatusOK, nil
+}
+
+// rejectStore rejects a failed store call, notFound means the value isn't listed.
+func rejectStore(resp http.ResponseWriter, err, notFound error) {
+	if errors.Is(err, notFound) {
+		rejectAdmin(resp, http.StatusNotFound, err)
+		return
+	}
+	rejectAdmin(resp, http.StatusInternalServerError, err)
+}
+
+func queryInt(req *http.Request, name string, fallback int) (int, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a positive number", name)
+	}
+	return n, nil
+}
+
+func rejectAdmin(resp http.ResponseWriter, status int, err error) {
+	resp.WriteHeader(status)
+	json.NewEncoder(resp).Encode(AdminResponse{Status: "rejected", Error: err.Error()})
+}
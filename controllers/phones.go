@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/phone"
+	"github.com/ilivestrong/rules-engine/rules"
+)
+
+const (
+	// approvedPhonesPath is where the handler is mounted, /admin/approved-phones/{phone} addresses one phone.
+	approvedPhonesPath = "/admin/approved-phones/"
+
+	DefaultApprovedPhonesPageSize = 100
+	MaxApprovedPhonesPageSize     = 1000
+	MaxApprovedPhonesImport       = 10000
+)
+
+type (
+	// ApprovedPhonesHandler manages the approved phone list of each product at runtime.
+	ApprovedPhonesHandler struct {
+		Products *rules.Products
+	}
+
+	// ApprovedPhoneRequest approves a phone.
+	ApprovedPhoneRequest struct {
+		Phone string `json:"phone"`
+		ListingRequest
+	}
+
+	ApprovedPhoneResponse struct {
+		Status   string                 `json:"status"`
+		Product  string                 `json:"product,omitempty"`
+		Phone    *helpers.ApprovedPhone `json:"phone,omitempty"`
+		Imported int                    `json:"imported,omitempty"`
+	}
+
+	ApprovedPhonesPage struct {
+		Product string                  `json:"product"`
+		Total   int                     `json:"total"`
+		Offset  int                     `json:"offset"`
+		Limit   int                     `json:"limit"`
+		Phones  []helpers.ApprovedPhone `json:"phones"`
+	}
+)
+
+func (handler *ApprovedPhonesHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Set("Content-Type", "application/json")
+	rulesEngine, product, err := handler.Products.Engine(req.URL.Query().Get("product"))
+	if err != nil {
+		rejectAdmin(resp, http.StatusNotFound, err)
+		return
+	}
+	// the product's rules engine checks the same store, so changes apply to the next application
+	store := rulesEngine.ApprovedPhones()
+
+	number := ""
+	if rest := strings.TrimPrefix(req.URL.Path, approvedPhonesPath); rest != req.URL.Path {
+		number = strings.Trim(rest, "/")
+	}
+
+	switch {
+	case number == "" && req.Method == http.MethodGet:
+		handler.list(resp, req, store, product)
+	case number == "" && req.Method == http.MethodPost:
+		handler.add(resp, req, store, product)
+	case number == "import" && req.Method == http.MethodPost:
+		handler.bulkImport(resp, req, store, product)
+	case number != "" && req.Method == http.MethodGet:
+		handler.check(resp, req, store, product, number)
+	case number != "" && req.Method == http.MethodDelete:
+		handler.remove(resp, req, store, product, number)
+	default:
+		resp.WriteHeader(http.StatusNotImplemented)
+		fmt.Fprint(resp, "Not implemented")
+	}
+}
+
+func (handler *ApprovedPhonesHandler) list(resp http.ResponseWriter, req *http.Request, store helpers.ApprovedPhoneStore, product string) {
+	offset, limit, err := pageParams(req, DefaultApprovedPhonesPageSize, MaxApprovedPhonesPageSize)
+	if err != nil {
+		rejectAdmin(resp, http.StatusBadRequest, err)
+		return
+	}
+
+	phones, total, err := store.List(req.Context(), offset, limit)
+	if err != nil {
+		rejectAdmin(resp, http.StatusInternalServerError, err)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(ApprovedPhonesPage{Product: product, Total: total, Offset: offset, Limit: limit, Phones: phones})
+}
+
+func (handler *ApprovedPhonesHandler) add(resp http.ResponseWriter, req *http.Request, store helpers.ApprovedPhoneStore, product string) {
+	var body ApprovedPhoneRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		rejectAdmin(resp, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		return
+	}
+	phone, err := body.approvedPhone(helpers.SourceAdmin, time.Now())
+	if err != nil {
+		rejectAdmin(resp, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := store.Add(req.Context(), phone); err != nil {
+		rejectAdmin(resp, http.StatusInternalServerError, err)
+		return
+	}
+	resp.WriteHeader(http.StatusCreated)
+	json.NewEncoder(resp).Encode(ApprovedPhoneResponse{Status: "approved", Product: product, Phone: &phone})
+}
+
+// bulkImport approves a JSON array of phones. Nothing is saved unless every phone is valid.
+func (handler *ApprovedPhonesHandler) bulkImport(resp http.ResponseWriter, req *http.Request, store helpers.ApprovedPhoneStore, product string) {
+	body, status, err := decodeImport[ApprovedPhoneRequest](req, MaxApprovedPhonesImport, "phones")
+	if err != nil {
+		rejectAdmin(resp, status, err)
+		return
+	}
+
+	now := time.Now()
+	phones := make([]helpers.ApprovedPhone, len(body))
+	for i, item := range body {
+		phone, err := item.approvedPhone(helpers.SourceImport, now)
+		if err != nil {
+			rejectAdmin(resp, http.StatusBadRequest, fmt.Errorf("item %d: %v", i, err))
+			return
+		}
+		phones[i] = phone
+	}
+
+	if err := store.Add(req.Context(), phones...); err != nil {
+		rejectAdmin(resp, http.StatusInternalServerError, err)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(ApprovedPhoneResponse{Status: "imported", Product: product, Imported: len(phones)})
+}
+
+func (handler *ApprovedPhonesHandler) check(resp http.ResponseWriter, req *http.Request, store helpers.ApprovedPhoneStore, product, number string) {
+	approved, err := store.Get(req.Context(), number)
+	if err != nil {
+		rejectStore(resp, err, helpers.ErrPhoneNotFound)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(ApprovedPhoneResponse{Status: "approved", Product: product, Phone: approved})
+}
+
+func (handler *ApprovedPhonesHandler) remove(resp http.ResponseWriter, req *http.Request, store helpers.ApprovedPhoneStore, product, number string) {
+	err := store.Remove(req.Context(), number)
+	if err != nil {
+		rejectStore(resp, err, helpers.ErrPhoneNotFound)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+	json.NewEncoder(resp).Encode(ApprovedPhoneResponse{Status: "removed", Product: product, Phone: &helpers.ApprovedPhone{Phone: phone.Normalize(number)}})
+}
+
+// approvedPhone checks the request, the phone is kept in its canonical form.
+func (apr ApprovedPhoneRequest) approvedPhone(source string, now time.Time) (helpers.ApprovedPhone, error) {
+	approved := helpers.ApprovedPhone{Phone: strings.TrimSpace(apr.Phone)}
+	if approved.Phone == "" {
+		return approved, errors.New("phone is required")
+	}
+	number, err := phone.Parse(approved.Phone, phone.FirstDigit)
+	if err != nil {
+		return approved, err
+	}
+	approved.Phone = number.Canonical()
+	approved.Listing, err = apr.listing(source, now)
+	return approved, err
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/rules"
+	"github.com/ilivestrong/rules-engine/stream"
+)
+
+type (
+	// StreamApprovalHandler answers a stream of applicants with a stream of decisions. Timeout is how long the
+	// stream may go without reading a line or writing a decision, 0 lets it wait forever.
+	StreamApprovalHandler struct {
+		Products *rules.Products
+		Workers  int
+		Timeout  time.Duration
+	}
+
+	// rollingDeadline moves the connection's deadlines forward on every read and write, so a stream lasts as long
+	// as it keeps moving while a client that stalls for longer than timeout is dropped.
+	rollingDeadline struct {
+		body    io.Reader
+		resp    http.ResponseWriter
+		rc      *http.ResponseController
+		timeout time.Duration
+	}
+)
+
+func (handler *StreamApprovalHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		resp.WriteHeader(http.StatusNotImplemented)
+		fmt.Fprint(resp, "Not implemented")
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/x-ndjson")
+	rulesEngine, _, err := handler.Products.Engine(req.URL.Query().Get("product"))
+	if err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(resp).Encode(stream.Result{Status: stream.StatusInvalid, Error: err.Error()})
+		return
+	}
+	verifyOpts, err := verifyOptions(req)
+	if err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(resp).Encode(stream.Result{Status: stream.StatusInvalid, Error: err.Error()})
+		return
+	}
+
+	explain, _ := strconv.ParseBool(req.URL.Query().Get("explain"))
+	opts := stream.Options{Workers: handler.Workers, Explain: explain, VerifyOptions: verifyOpts}
+
+	rc := http.NewResponseController(resp)
+	if req.ProtoMajor < 2 {
+		// HTTP/1.x otherwise discards the rest of the request body once the first decision is sent
+		rc.EnableFullDuplex()
+	}
+	var in io.Reader = req.Body
+	var out io.Writer = resp
+	if handler.Timeout > 0 {
+		// decisions go out as they are made, so the server's timeouts would cut a long stream short
+		deadline := &rollingDeadline{body: req.Body, resp: resp, rc: rc, timeout: handler.Timeout}
+		deadline.extendRead()
+		deadline.extendWrite()
+		in, out = deadline, deadline
+	}
+
+	resp.WriteHeader(http.StatusOK)
+	if _, err := stream.Process(req.Context(), rulesEngine, in, out, opts); err != nil {
+		fmt.Printf("decision stream stopped: %v\n", err)
+	}
+}
+
+func (rd *rollingDeadline) Read(p []byte) (int, error) {
+	rd.extendRead()
+	return rd.body.Read(p)
+}
+
+func (rd *rollingDeadline) Write(p []byte) (int, error) {
+	rd.extendWrite()
+	return rd.resp.Write(p)
+}
+
+func (rd *rollingDeadline) Flush() {
+	rd.extendWrite()
+	rd.rc.Flush()
+}
+
+func (rd *rollingDeadline) extendRead() {
+	rd.rc.SetReadDeadline(time.Now().Add(rd.timeout))
+}
+
+func (rd *rollingDeadline) extendWrite() {
+	rd.rc.SetWriteDeadline(time.Now().Add(rd.timeout))
+}
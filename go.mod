@@ -1,8 +1,9 @@
 module github.com/ilivestrong/rules-engine
 
-go 1.18
+go 1.21
 
 require (
+	github.com/jackc/pgx/v5 v5.4.1
 	github.com/joho/godotenv v1.4.0
 	github.com/stretchr/testify v1.8.2
 )
@@ -11,7 +12,6 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
-	github.com/jackc/pgx/v5 v5.4.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/objx v0.5.0 // indirect
 	golang.org/x/crypto v0.9.0 // indirect
@@ -0,0 +1,208 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/phone"
+)
+
+var ErrPhoneNotFound = errors.New("phone is not approved")
+
+type (
+	// ApprovedPhone is a phone that bypasses the rules.
+	ApprovedPhone struct {
+		Phone string `json:"phone"`
+		Listing
+	}
+
+	// ApprovedPhoneStore manages the phones that bypass the rules. Expired phones count as not approved. Add
+	// replaces the entry of a phone that is already approved, AddIfAbsent leaves it as it is.
+	ApprovedPhoneStore interface {
+		List(ctx context.Context, offset, limit int) ([]ApprovedPhone, int, error)
+		Get(ctx context.Context, phone string) (*ApprovedPhone, error)
+		Add(ctx context.Context, phones ...ApprovedPhone) error
+		AddIfAbsent(ctx context.Context, phones ...ApprovedPhone) error
+		Remove(ctx context.Context, phone string) error
+	}
+
+	fileApprovedPhoneStore struct {
+		file listFile[string, ApprovedPhone]
+	}
+
+	memoryApprovedPhoneStore struct {
+		mu     sync.RWMutex
+		phones map[string]ApprovedPhone
+	}
+)
+
+// approvedPhonesFileMu serialises read-modify-write cycles on approved phone list files.
+var approvedPhonesFileMu sync.Mutex
+
+// stamped normalizes phones and sets when they were added, unless the caller already did.
+func stamped(phones []ApprovedPhone, now time.Time) []ApprovedPhone {
+	out := make([]ApprovedPhone, len(phones))
+	for i, approved := range phones {
+		approved.Phone = phone.Normalize(approved.Phone)
+		approved.stamp(now)
+		out[i] = approved
+	}
+	return out
+}
+
+// NewFileApprovedPhoneStore keeps approved phones in a JSON file, e.g. rules/approved-phone-list.json.
+func NewFileApprovedPhoneStore(path string) *fileApprovedPhoneStore {
+	return &fileApprovedPhoneStore{file: listFile[string, ApprovedPhone]{
+		path:     path,
+		name:     "approved phones",
+		mu:       &approvedPhonesFileMu,
+		notFound: ErrPhoneNotFound,
+		read:     readApprovedPhones,
+		key:      func(approved ApprovedPhone) string { return approved.Phone },
+		encode:   encodeApprovedPhones,
+	}}
+}
+
+func (fs *fileApprovedPhoneStore) List(ctx context.Context, offset, limit int) ([]ApprovedPhone, int, error) {
+	return fs.file.list(offset, limit)
+}
+
+func (fs *fileApprovedPhoneStore) Get(ctx context.Context, number string) (*ApprovedPhone, error) {
+	return fs.file.get(phone.Normalize(number))
+}
+
+func (fs *fileApprovedPhoneStore) Add(ctx context.Context, phones ...ApprovedPhone) error {
+	return fs.file.update(func(entries map[string]ApprovedPhone) error {
+		for _, phone := range stamped(phones, time.Now()) {
+			entries[phone.Phone] = phone
+		}
+		return nil
+	})
+}
+
+func (fs *fileApprovedPhoneStore) AddIfAbsent(ctx context.Context, phones ...ApprovedPhone) error {
+	return fs.file.update(func(entries map[string]ApprovedPhone) error {
+		now := time.Now()
+		for _, phone := range stamped(phones, now) {
+			if existing, exist := entries[phone.Phone]; !exist || existing.Expired(now) {
+				entries[phone.Phone] = phone
+			}
+		}
+		return nil
+	})
+}
+
+func (fs *fileApprovedPhoneStore) Remove(ctx context.Context, number string) error {
+	return fs.file.remove(phone.Normalize(number))
+}
+
+// NewMemoryApprovedPhoneStore keeps approved phones in memory only, e.g. for tests or offline simulations.
+func NewMemoryApprovedPhoneStore(phones ...ApprovedPhone) *memoryApprovedPhoneStore {
+	ms := &memoryApprovedPhoneStore{phones: make(map[string]ApprovedPhone, len(phones))}
+	for _, approved := range phones {
+		approved.Phone = phone.Normalize(approved.Phone)
+		ms.phones[approved.Phone] = approved
+	}
+	return ms
+}
+
+func (ms *memoryApprovedPhoneStore) List(ctx context.Context, offset, limit int) ([]ApprovedPhone, int, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	active := make([]ApprovedPhone, 0, len(ms.phones))
+	for _, phone := range ms.phones {
+		active = append(active, phone)
+	}
+	active = unexpired(active, time.Now())
+	sort.Slice(active, func(i, j int) bool { return active[i].Phone < active[j].Phone })
+	return page(active, offset, limit), len(active), nil
+}
+
+func (ms *memoryApprovedPhoneStore) Get(ctx context.Context, number string) (*ApprovedPhone, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	approved, exist := ms.phones[phone.Normalize(number)]
+	if !exist || approved.Expired(time.Now()) {
+		return nil, ErrPhoneNotFound
+	}
+	return &approved, nil
+}
+
+func (ms *memoryApprovedPhoneStore) Add(ctx context.Context, phones ...ApprovedPhone) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, phone := range stamped(phones, time.Now()) {
+		ms.phones[phone.Phone] = phone
+	}
+	return nil
+}
+
+func (ms *memoryApprovedPhoneStore) AddIfAbsent(ctx context.Context, phones ...ApprovedPhone) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now()
+	for _, phone := range stamped(phones, now) {
+		if existing, exist := ms.phones[phone.Phone]; !exist || existing.Expired(now) {
+			ms.phones[phone.Phone] = phone
+		}
+	}
+	return nil
+}
+
+func (ms *memoryApprovedPhoneStore) Remove(ctx context.Context, number string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	number = phone.Normalize(number)
+	if approved, exist := ms.phones[number]; !exist || approved.Expired(time.Now()) {
+		return ErrPhoneNotFound
+	}
+	delete(ms.phones, number)
+	return nil
+}
+
+// readApprovedPhones reads an approved phone list sorted by phone, `false` entries are left out. Phones are
+// normalized, so lists written before normalization still match.
+func readApprovedPhones(path string) ([]ApprovedPhone, error) {
+	fileData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load approved list phones: %v", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(fileData, &raw); err != nil {
+		return nil, fmt.Errorf("invalid approved phone list: %v", err)
+	}
+
+	phones := make([]ApprovedPhone, 0, len(raw))
+	for number, value := range raw {
+		listing, approved, err := decodeListing(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid approved phone list entry %s: %v", number, err)
+		}
+		if approved {
+			phones = append(phones, ApprovedPhone{Phone: phone.Normalize(number), Listing: listing})
+		}
+	}
+	sort.Slice(phones, func(i, j int) bool { return phones[i].Phone < phones[j].Phone })
+	return phones, nil
+}
+
+// encodeApprovedPhones keys the approved phone list by phone.
+func encodeApprovedPhones(entries map[string]ApprovedPhone) any {
+	raw := make(map[string]any, len(entries))
+	for number, approved := range entries {
+		raw[number] = approved.fileEntry()
+	}
+	return raw
+}
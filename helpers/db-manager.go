@@ -2,18 +2,35 @@ package helpers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+
+	"github.com/ilivestrong/rules-engine/phone"
 )
 
 type (
-	RulesEngineRepo interface {
-		AddApprovedPhone(ctx context.Context, phone string) error
-	}
-
 	rulesEngineRepo struct {
 		Conn *pgx.Conn
+
+		// a pgx connection can't be shared by concurrent queries
+		mu sync.Mutex
+	}
+
+	// postgresApprovedPhones keeps the approved phones of one product in the approved_phones table, sharing the
+	// repo's connection.
+	postgresApprovedPhones struct {
+		repo    *rulesEngineRepo
+		product string
+	}
+
+	// postgresDenyList keeps the deny list of one product in the denied_values table, sharing the repo's connection.
+	postgresDenyList struct {
+		repo    *rulesEngineRepo
+		product string
 	}
 
 	Config struct {
@@ -21,23 +38,339 @@ type (
 		Pass   string
 		Host   string
 		DBName string
+		// DefaultProduct owns the rows saved before approved phones and deny lists were kept per product.
+		DefaultProduct string
 	}
 )
 
-func (rer *rulesEngineRepo) AddApprovedPhone(ctx context.Context, phone string) error {
-	_, err := rer.Conn.Exec(ctx, fmt.Sprintf("INSERT INTO approved_phones VALUES('%s')", phone))
-	return err
+const (
+	// notExpired matches the rows that have not expired.
+	notExpired   = "(expires_at IS NULL OR expires_at > now())"
+	phoneColumns = "phone, source, added_by, added_at, expires_at, reason"
+	denyColumns  = "field, value, source, added_by, added_at, expires_at, reason"
+)
+
+// ApprovedPhones is the Postgres approved phone store of product.
+func (rer *rulesEngineRepo) ApprovedPhones(product string) ApprovedPhoneStore {
+	return &postgresApprovedPhones{repo: rer, product: product}
+}
+
+func (pa *postgresApprovedPhones) List(ctx context.Context, offset, limit int) ([]ApprovedPhone, int, error) {
+	pa.repo.mu.Lock()
+	defer pa.repo.mu.Unlock()
+
+	var total int
+	if err := pa.repo.Conn.QueryRow(ctx, "SELECT count(*) FROM approved_phones WHERE product = $1 AND "+notExpired, pa.product).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count approved phones: %v", err)
+	}
+
+	query := "SELECT " + phoneColumns + " FROM approved_phones WHERE product = $1 AND " + notExpired + " ORDER BY phone OFFSET $2"
+	args := []any{pa.product, offset}
+	if limit > 0 {
+		query += " LIMIT $3"
+		args = append(args, limit)
+	}
+	rows, err := pa.repo.Conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list approved phones: %v", err)
+	}
+	defer rows.Close()
+
+	phones := []ApprovedPhone{}
+	for rows.Next() {
+		var phone ApprovedPhone
+		if err := scanPhone(rows, &phone); err != nil {
+			return nil, 0, fmt.Errorf("failed to list approved phones: %v", err)
+		}
+		phones = append(phones, phone)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list approved phones: %v", err)
+	}
+	return phones, total, nil
+}
+
+func (pa *postgresApprovedPhones) Get(ctx context.Context, number string) (*ApprovedPhone, error) {
+	pa.repo.mu.Lock()
+	defer pa.repo.mu.Unlock()
+
+	var approved ApprovedPhone
+	row := pa.repo.Conn.QueryRow(ctx, "SELECT "+phoneColumns+" FROM approved_phones WHERE product = $1 AND phone = $2 AND "+notExpired+" LIMIT 1", pa.product, phone.Normalize(number))
+	err := scanPhone(row, &approved)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPhoneNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check approved phone: %v", err)
+	}
+	return &approved, nil
+}
+
+// Add saves the phones in one transaction, a phone that is already approved gets the new expiry.
+func (pa *postgresApprovedPhones) Add(ctx context.Context, phones ...ApprovedPhone) error {
+	pa.repo.mu.Lock()
+	defer pa.repo.mu.Unlock()
+
+	tx, err := pa.repo.Conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save approved phones: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, phone := range stamped(phones, time.Now()) {
+		if _, err := tx.Exec(ctx, "DELETE FROM approved_phones WHERE product = $1 AND phone = $2", pa.product, phone.Phone); err != nil {
+			return fmt.Errorf("failed to save approved phone %s: %v", phone.Phone, err)
+		}
+		_, err := tx.Exec(ctx,
+			"INSERT INTO approved_phones (product, "+phoneColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			pa.product, phone.Phone, phone.Source, phone.AddedBy, phone.AddedAt, phone.ExpiresAt, phone.Reason,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save approved phone %s: %v", phone.Phone, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save approved phones: %v", err)
+	}
+	return nil
+}
+
+// AddIfAbsent saves the phones that are not approved yet in one transaction, an expired phone is replaced.
+func (pa *postgresApprovedPhones) AddIfAbsent(ctx context.Context, phones ...ApprovedPhone) error {
+	pa.repo.mu.Lock()
+	defer pa.repo.mu.Unlock()
+
+	tx, err := pa.repo.Conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save approved phones: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, phone := range stamped(phones, time.Now()) {
+		if _, err := tx.Exec(ctx, "DELETE FROM approved_phones WHERE product = $1 AND phone = $2 AND NOT "+notExpired, pa.product, phone.Phone); err != nil {
+			return fmt.Errorf("failed to save approved phone %s: %v", phone.Phone, err)
+		}
+		_, err := tx.Exec(ctx,
+			"INSERT INTO approved_phones (product, "+phoneColumns+") SELECT $1, $2, $3, $4, $5, $6, $7"+
+				" WHERE NOT EXISTS (SELECT 1 FROM approved_phones WHERE product = $1 AND phone = $2)",
+			pa.product, phone.Phone, phone.Source, phone.AddedBy, phone.AddedAt, phone.ExpiresAt, phone.Reason,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save approved phone %s: %v", phone.Phone, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save approved phones: %v", err)
+	}
+	return nil
+}
+
+func (pa *postgresApprovedPhones) Remove(ctx context.Context, number string) error {
+	pa.repo.mu.Lock()
+	defer pa.repo.mu.Unlock()
+
+	tag, err := pa.repo.Conn.Exec(ctx, "DELETE FROM approved_phones WHERE product = $1 AND phone = $2 AND "+notExpired, pa.product, phone.Normalize(number))
+	if err != nil {
+		return fmt.Errorf("failed to remove approved phone: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPhoneNotFound
+	}
+	return nil
+}
+
+// migrate creates the approved_phones and denied_values tables. An approved_phones table from before phones had
+// details and products gets the missing columns, see adoptPhones for its rows.
+func (rer *rulesEngineRepo) migrate(ctx context.Context, defaultProduct string) error {
+	err := rer.migrateTable(ctx, "approved_phones",
+		"CREATE TABLE IF NOT EXISTS approved_phones (phone TEXT NOT NULL)",
+		"ALTER TABLE approved_phones ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE approved_phones ADD COLUMN IF NOT EXISTS added_by TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE approved_phones ADD COLUMN IF NOT EXISTS added_at TIMESTAMPTZ NOT NULL DEFAULT now()",
+		"ALTER TABLE approved_phones ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ",
+		"ALTER TABLE approved_phones ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE approved_phones ADD COLUMN IF NOT EXISTS product TEXT",
+	)
+	if err != nil {
+		return err
+	}
+	if err := rer.adoptPhones(ctx, defaultProduct); err != nil {
+		return err
+	}
+	err = rer.migrateTable(ctx, "approved_phones",
+		"ALTER TABLE approved_phones ALTER COLUMN product SET NOT NULL",
+		"CREATE INDEX IF NOT EXISTS approved_phones_product_phone ON approved_phones (product, phone)",
+	)
+	if err != nil {
+		return err
+	}
+
+	return rer.migrateTable(ctx, "denied_values",
+		`CREATE TABLE IF NOT EXISTS denied_values (
+			product TEXT NOT NULL,
+			field TEXT NOT NULL,
+			value TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			added_by TEXT NOT NULL DEFAULT '',
+			added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			expires_at TIMESTAMPTZ,
+			reason TEXT NOT NULL DEFAULT ''
+		)`,
+		"CREATE UNIQUE INDEX IF NOT EXISTS denied_values_product_field_value ON denied_values (product, field, value)",
+	)
+}
+
+func (rer *rulesEngineRepo) migrateTable(ctx context.Context, table string, statements ...string) error {
+	for _, statement := range statements {
+		if _, err := rer.Conn.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to migrate %s: %v", table, err)
+		}
+	}
+	return nil
+}
+
+// adoptPhones gives the phones saved before approved phones were kept per product to defaultProduct. They were
+// saved as they were sent, so they are rewritten in their canonical form to be found again.
+func (rer *rulesEngineRepo) adoptPhones(ctx context.Context, defaultProduct string) error {
+	rows, err := rer.Conn.Query(ctx, "SELECT DISTINCT phone FROM approved_phones WHERE product IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to migrate approved_phones: %v", err)
+	}
+	numbers, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("failed to migrate approved_phones: %v", err)
+	}
+	for _, number := range numbers {
+		_, err := rer.Conn.Exec(ctx, "UPDATE approved_phones SET phone = $1, product = $2 WHERE phone = $3 AND product IS NULL",
+			phone.Normalize(number), defaultProduct, number)
+		if err != nil {
+			return fmt.Errorf("failed to migrate approved phone %s: %v", number, err)
+		}
+	}
+	return nil
+}
+
+// DenyList is the Postgres deny list store of product.
+func (rer *rulesEngineRepo) DenyList(product string) DenyListStore {
+	return &postgresDenyList{repo: rer, product: product}
+}
+
+func (pd *postgresDenyList) List(ctx context.Context, offset, limit int) ([]DeniedValue, int, error) {
+	pd.repo.mu.Lock()
+	defer pd.repo.mu.Unlock()
+
+	var total int
+	if err := pd.repo.Conn.QueryRow(ctx, "SELECT count(*) FROM denied_values WHERE product = $1 AND "+notExpired, pd.product).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count denied values: %v", err)
+	}
+
+	query := "SELECT " + denyColumns + " FROM denied_values WHERE product = $1 AND " + notExpired + " ORDER BY field, value OFFSET $2"
+	args := []any{pd.product, offset}
+	if limit > 0 {
+		query += " LIMIT $3"
+		args = append(args, limit)
+	}
+	rows, err := pd.repo.Conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list denied values: %v", err)
+	}
+	defer rows.Close()
+
+	values := []DeniedValue{}
+	for rows.Next() {
+		var value DeniedValue
+		if err := scanDenied(rows, &value); err != nil {
+			return nil, 0, fmt.Errorf("failed to list denied values: %v", err)
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list denied values: %v", err)
+	}
+	return values, total, nil
+}
+
+func (pd *postgresDenyList) Get(ctx context.Context, field, value string) (*DeniedValue, error) {
+	pd.repo.mu.Lock()
+	defer pd.repo.mu.Unlock()
+
+	var denied DeniedValue
+	row := pd.repo.Conn.QueryRow(ctx, "SELECT "+denyColumns+" FROM denied_values WHERE product = $1 AND field = $2 AND value = $3 AND "+notExpired, pd.product, field, normalizedValue(field, value))
+	err := scanDenied(row, &denied)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotDenied
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check deny list: %v", err)
+	}
+	return &denied, nil
+}
+
+func (pd *postgresDenyList) Add(ctx context.Context, values ...DeniedValue) error {
+	pd.repo.mu.Lock()
+	defer pd.repo.mu.Unlock()
+
+	tx, err := pd.repo.Conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save denied values: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, value := range stampedDenied(values, time.Now()) {
+		_, err := tx.Exec(ctx, `INSERT INTO denied_values (product, `+denyColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (product, field, value) DO UPDATE SET source = $4, added_by = $5, added_at = $6, expires_at = $7, reason = $8`,
+			pd.product, value.Field, value.Value, value.Source, value.AddedBy, value.AddedAt, value.ExpiresAt, value.Reason,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save denied value %s %s: %v", value.Field, value.Value, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save denied values: %v", err)
+	}
+	return nil
+}
+
+func (pd *postgresDenyList) Remove(ctx context.Context, field, value string) error {
+	pd.repo.mu.Lock()
+	defer pd.repo.mu.Unlock()
+
+	tag, err := pd.repo.Conn.Exec(ctx, "DELETE FROM denied_values WHERE product = $1 AND field = $2 AND value = $3 AND "+notExpired, pd.product, field, normalizedValue(field, value))
+	if err != nil {
+		return fmt.Errorf("failed to remove denied value: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotDenied
+	}
+	return nil
 }
 
 func NewRulesEngineRepo(ctx context.Context, config *Config) (*rulesEngineRepo, error) {
 	dbURL := fmt.Sprintf("postgres://%s:%s@%s:5432/%s", config.User, config.Pass, config.Host, config.DBName)
 	fmt.Println(dbURL)
+	return connectRepo(ctx, dbURL, config.DefaultProduct)
+}
+
+func connectRepo(ctx context.Context, dbURL, defaultProduct string) (*rulesEngineRepo, error) {
 	c, err := pgx.Connect(ctx, dbURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialise database connection: %v", err)
 	}
 
-	return &rulesEngineRepo{
+	repo := &rulesEngineRepo{
 		Conn: c,
-	}, nil
+	}
+	if err := repo.migrate(ctx, defaultProduct); err != nil {
+		c.Close(ctx)
+		return nil, err
+	}
+	return repo, nil
+}
+
+func scanPhone(row pgx.Row, phone *ApprovedPhone) error {
+	return row.Scan(&phone.Phone, &phone.Source, &phone.AddedBy, &phone.AddedAt, &phone.ExpiresAt, &phone.Reason)
+}
+
+func scanDenied(row pgx.Row, value *DeniedValue) error {
+	return row.Scan(&value.Field, &value.Value, &value.Source, &value.AddedBy, &value.AddedAt, &value.ExpiresAt, &value.Reason)
 }
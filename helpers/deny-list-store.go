@@ -0,0 +1,218 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/phone"
+)
+
+// phoneNumberField values are normalized like approved phones, so any way of writing the number matches.
+const phoneNumberField = "phone_number"
+
+var ErrNotDenied = errors.New("value is not denied")
+
+type (
+	// DeniedValue blocks applicants whose Field (an applicant JSON field such as phone_number) equals Value.
+	DeniedValue struct {
+		Field string `json:"field"`
+		Value string `json:"value"`
+		Listing
+	}
+
+	// DenyListStore manages the applicant values that are declined before any rule runs. Expired values count as
+	// not denied.
+	DenyListStore interface {
+		List(ctx context.Context, offset, limit int) ([]DeniedValue, int, error)
+		Get(ctx context.Context, field, value string) (*DeniedValue, error)
+		Add(ctx context.Context, values ...DeniedValue) error
+		Remove(ctx context.Context, field, value string) error
+	}
+
+	fileDenyListStore struct {
+		file listFile[denyKey, DeniedValue]
+	}
+
+	memoryDenyListStore struct {
+		mu     sync.RWMutex
+		values map[denyKey]DeniedValue
+	}
+
+	denyKey struct {
+		field string
+		value string
+	}
+)
+
+var denyListFileMu sync.Mutex
+
+func (dv DeniedValue) key() denyKey {
+	return denyKey{field: dv.Field, value: dv.Value}
+}
+
+// NewFileDenyListStore keeps denied values in a JSON file, e.g. rules/deny-list.json. A missing file is an empty
+// deny list and is created on the first change.
+func NewFileDenyListStore(path string) *fileDenyListStore {
+	return &fileDenyListStore{file: listFile[denyKey, DeniedValue]{
+		path:     path,
+		name:     "deny list",
+		mu:       &denyListFileMu,
+		notFound: ErrNotDenied,
+		read:     readDenyList,
+		key:      DeniedValue.key,
+		encode:   encodeDenyList,
+	}}
+}
+
+func (fs *fileDenyListStore) List(ctx context.Context, offset, limit int) ([]DeniedValue, int, error) {
+	return fs.file.list(offset, limit)
+}
+
+func (fs *fileDenyListStore) Get(ctx context.Context, field, value string) (*DeniedValue, error) {
+	return fs.file.get(denyKey{field: field, value: normalizedValue(field, value)})
+}
+
+func (fs *fileDenyListStore) Add(ctx context.Context, values ...DeniedValue) error {
+	return fs.file.update(func(entries map[denyKey]DeniedValue) error {
+		for _, value := range stampedDenied(values, time.Now()) {
+			entries[value.key()] = value
+		}
+		return nil
+	})
+}
+
+func (fs *fileDenyListStore) Remove(ctx context.Context, field, value string) error {
+	return fs.file.remove(denyKey{field: field, value: normalizedValue(field, value)})
+}
+
+// NewMemoryDenyListStore keeps denied values in memory only, e.g. for tests or offline simulations.
+func NewMemoryDenyListStore(values ...DeniedValue) *memoryDenyListStore {
+	ms := &memoryDenyListStore{values: make(map[denyKey]DeniedValue, len(values))}
+	for _, value := range values {
+		value.Value = normalizedValue(value.Field, value.Value)
+		ms.values[value.key()] = value
+	}
+	return ms
+}
+
+func (ms *memoryDenyListStore) List(ctx context.Context, offset, limit int) ([]DeniedValue, int, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	values := make([]DeniedValue, 0, len(ms.values))
+	for _, value := range ms.values {
+		values = append(values, value)
+	}
+	sortDenied(values)
+	active := unexpired(values, time.Now())
+	return page(active, offset, limit), len(active), nil
+}
+
+func (ms *memoryDenyListStore) Get(ctx context.Context, field, value string) (*DeniedValue, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	denied, exist := ms.values[denyKey{field: field, value: normalizedValue(field, value)}]
+	if !exist || denied.Expired(time.Now()) {
+		return nil, ErrNotDenied
+	}
+	return &denied, nil
+}
+
+func (ms *memoryDenyListStore) Add(ctx context.Context, values ...DeniedValue) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for _, value := range stampedDenied(values, time.Now()) {
+		ms.values[value.key()] = value
+	}
+	return nil
+}
+
+func (ms *memoryDenyListStore) Remove(ctx context.Context, field, value string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	key := denyKey{field: field, value: normalizedValue(field, value)}
+	if denied, exist := ms.values[key]; !exist || denied.Expired(time.Now()) {
+		return ErrNotDenied
+	}
+	delete(ms.values, key)
+	return nil
+}
+
+// readDenyList reads a deny list file sorted by field and value, `false` entries are left out.
+func readDenyList(path string) ([]DeniedValue, error) {
+	fileData, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deny list: %v", err)
+	}
+
+	var raw map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(fileData, &raw); err != nil {
+		return nil, fmt.Errorf("invalid deny list: %v", err)
+	}
+
+	var values []DeniedValue
+	for field, fieldValues := range raw {
+		for value, data := range fieldValues {
+			listing, denied, err := decodeListing(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid deny list entry %s %s: %v", field, value, err)
+			}
+			if denied {
+				values = append(values, DeniedValue{Field: field, Value: normalizedValue(field, value), Listing: listing})
+			}
+		}
+	}
+	sortDenied(values)
+	return values, nil
+}
+
+// encodeDenyList keys the deny list by field and then value.
+func encodeDenyList(entries map[denyKey]DeniedValue) any {
+	raw := make(map[string]map[string]any)
+	for key, denied := range entries {
+		if raw[key.field] == nil {
+			raw[key.field] = make(map[string]any)
+		}
+		raw[key.field][key.value] = denied.fileEntry()
+	}
+	return raw
+}
+
+func stampedDenied(values []DeniedValue, now time.Time) []DeniedValue {
+	out := make([]DeniedValue, len(values))
+	for i, value := range values {
+		value.Value = normalizedValue(value.Field, value.Value)
+		value.stamp(now)
+		out[i] = value
+	}
+	return out
+}
+
+func normalizedValue(field, value string) string {
+	if field == phoneNumberField {
+		return phone.Normalize(value)
+	}
+	return value
+}
+
+func sortDenied(values []DeniedValue) {
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Field != values[j].Field {
+			return values[i].Field < values[j].Field
+		}
+		return values[i].Value < values[j].Value
+	})
+}
@@ -2,76 +2,35 @@ package helpers
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"io/ioutil"
-	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/ilivestrong/rules-engine/models"
 )
 
 type (
 	FileManager interface {
-		LoadRulesFromConfig() ([]models.RuleInfo, error)
-		ListApprovedPhones() (ApprovedPhones, error)
-		PersistApprovedPhone(phone string) error
+		LoadRulesFromConfig() (*models.RulesConfig, error)
+	}
+	defaultFileManager struct {
+		rulesConfig        string
+		approvedPhonesList string
 	}
-	defaultFileManager struct{}
-	ApprovedPhones     = map[string]bool
 )
 
-func (dfm *defaultFileManager) LoadRulesFromConfig() ([]models.RuleInfo, error) {
-	rulesConfig, _ := getJSONPaths()
-	data, err := ioutil.ReadFile(rulesConfig)
+func (dfm *defaultFileManager) LoadRulesFromConfig() (*models.RulesConfig, error) {
+	data, err := ioutil.ReadFile(dfm.rulesConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	var ruleInfos []models.RuleInfo
-	if err := json.Unmarshal(data, &ruleInfos); err != nil {
+	var config models.RulesConfig
+	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
-	return ruleInfos, nil
-}
-
-func (dfm *defaultFileManager) ListApprovedPhones() (ApprovedPhones, error) {
-	_, approvedPhonesListStore := getJSONPaths()
-	fileData, err := ioutil.ReadFile(approvedPhonesListStore)
-	if err != nil {
-		log.Fatal(err)
-		return nil, errors.New("failed to load approved list phones")
-	}
-
-	var phoneNumbers ApprovedPhones
-	err = json.Unmarshal(fileData, &phoneNumbers)
-	if err != nil {
-		log.Fatal(err)
-		return nil, errors.New("invalid approved phone list")
-	}
-	return phoneNumbers, nil
-}
-
-func (dfm *defaultFileManager) PersistApprovedPhone(phone string) error {
-	_, approvedPhonesListStore := getJSONPaths()
-	phoneNumbers, err := dfm.ListApprovedPhones()
-	if err != nil {
-		log.Fatal(err)
-		return err
-	}
-
-	phoneNumbers[phone] = true
-
-	jsonData, err := json.Marshal(phoneNumbers)
-	if err != nil {
-		log.Fatal(err)
-		return errors.New("failed to persist approved phone number")
-	}
-
-	err = ioutil.WriteFile(approvedPhonesListStore, jsonData, 0644)
-	if err != nil {
-		log.Fatal(err)
-		return errors.New("failed to persist approved phone number")
-	}
-	return nil
+	return &config, nil
 }
 
 func getJSONPaths() (rulesConfig string, approvedPhonesList string) {
@@ -88,6 +47,64 @@ func getJSONPaths() (rulesConfig string, approvedPhonesList string) {
 	return
 }
 
+func (dfm *defaultFileManager) RulesConfigPath() string {
+	return dfm.rulesConfig
+}
+
+// ApprovedPhonesPath is the approved phone list kept next to the rules, see NewFileApprovedPhoneStore.
+func (dfm *defaultFileManager) ApprovedPhonesPath() string {
+	return dfm.approvedPhonesList
+}
+
+// DenyListPath is the deny list kept next to the rules, see NewFileDenyListStore.
+func (dfm *defaultFileManager) DenyListPath() string {
+	return filepath.Join(filepath.Dir(dfm.rulesConfig), "deny-list.json")
+}
+
+// IndustryTaxonomyPath is the job industry taxonomy kept next to the rules.
+func (dfm *defaultFileManager) IndustryTaxonomyPath() string {
+	return filepath.Join(filepath.Dir(dfm.rulesConfig), "industries.json")
+}
+
 func NewFileManager() *defaultFileManager {
-	return &defaultFileManager{}
+	rulesConfig, approvedPhonesList := getJSONPaths()
+	return &defaultFileManager{
+		rulesConfig:        rulesConfig,
+		approvedPhonesList: approvedPhonesList,
+	}
+}
+
+// NewFileManagerWithPaths reads the rules and approved phone list from the given files instead of the rules folder.
+func NewFileManagerWithPaths(rulesConfig, approvedPhonesList string) *defaultFileManager {
+	return &defaultFileManager{
+		rulesConfig:        rulesConfig,
+		approvedPhonesList: approvedPhonesList,
+	}
+}
+
+// NewProductFileManager reads the rules and approved phone list of a product from its own folder.
+func NewProductFileManager(dir string) *defaultFileManager {
+	return NewFileManagerWithPaths(filepath.Join(dir, "rules.json"), filepath.Join(dir, "approved-phone-list.json"))
+}
+
+// ProductDirs finds the products configured under rulesDir/products, keyed by product name.
+func ProductDirs(rulesDir string) (map[string]string, error) {
+	productsDir := filepath.Join(rulesDir, "products")
+
+	entries, err := ioutil.ReadDir(productsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list products: %v", err)
+	}
+
+	dirs := make(map[string]string)
+	for _, entry := range entries {
+		dir := filepath.Join(productsDir, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, "rules.json")); entry.IsDir() && err == nil {
+			dirs[entry.Name()] = dir
+		}
+	}
+	return dirs, nil
 }
@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// WatchFile polls path every interval and calls onChange when its modification time or size changes.
+// It blocks until ctx is cancelled.
+func WatchFile(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	last, err := os.Stat(path)
+	if err != nil {
+		log.Printf("failed to watch %s: %v\n", path, err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				continue // file may be mid-replace, try again on the next tick
+			}
+			if last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+				continue
+			}
+			last = info
+			onChange()
+		}
+	}
+}
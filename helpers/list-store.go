@@ -0,0 +1,187 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// sources of listings
+const (
+	SourceAutoApproval = "auto_approval"
+	SourceAdmin        = "admin"
+	SourceImport       = "import"
+)
+
+type (
+	// Listing is who put a value on a list (approved phones, deny list), when and why. Values without an expiry
+	// stay listed until removed.
+	Listing struct {
+		Source    string     `json:"source,omitempty"`
+		AddedBy   string     `json:"added_by,omitempty"`
+		AddedAt   *time.Time `json:"added_at,omitempty"`
+		ExpiresAt *time.Time `json:"expires_at,omitempty"`
+		Reason    string     `json:"reason,omitempty"`
+	}
+
+	listed interface {
+		Expired(now time.Time) bool
+	}
+
+	// listFile is a list kept in a JSON file, entries of type T keyed by K. The file is read whole and replaced
+	// whole on every change, read decodes it and encode turns the entries back into what is written.
+	listFile[K comparable, T listed] struct {
+		path     string
+		name     string
+		mu       *sync.Mutex
+		notFound error
+		read     func(path string) ([]T, error)
+		key      func(T) K
+		encode   func(entries map[K]T) any
+	}
+)
+
+func (l Listing) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !l.ExpiresAt.After(now)
+}
+
+// stamp sets when the value was added, unless the caller already did.
+func (l *Listing) stamp(now time.Time) {
+	if l.AddedAt == nil {
+		addedAt := now.UTC()
+		l.AddedAt = &addedAt
+	}
+}
+
+// fileEntry is how the listing is kept in a list file, listings without any details stay plain `true` values.
+func (l Listing) fileEntry() any {
+	if l == (Listing{}) {
+		return true
+	}
+	return l
+}
+
+// decodeListing reads a list file entry, either `true`, `false` or an object with the details of the listing.
+func decodeListing(data json.RawMessage) (Listing, bool, error) {
+	var plain bool
+	if err := json.Unmarshal(data, &plain); err == nil {
+		return Listing{}, plain, nil
+	}
+	var listing Listing
+	if err := json.Unmarshal(data, &listing); err != nil {
+		return Listing{}, false, err
+	}
+	return listing, true, nil
+}
+
+func (lf listFile[K, T]) list(offset, limit int) ([]T, int, error) {
+	items, err := lf.read(lf.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	active := unexpired(items, time.Now())
+	return page(active, offset, limit), len(active), nil
+}
+
+func (lf listFile[K, T]) get(key K) (*T, error) {
+	items, err := lf.read(lf.path)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, item := range items {
+		if lf.key(item) == key && !item.Expired(now) {
+			return &item, nil
+		}
+	}
+	return nil, lf.notFound
+}
+
+// update applies update to the entries of the list and writes them back, expired entries are dropped.
+func (lf listFile[K, T]) update(update func(entries map[K]T) error) error {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+
+	items, err := lf.read(lf.path)
+	if err != nil {
+		return err
+	}
+	entries := make(map[K]T, len(items))
+	for _, item := range items {
+		entries[lf.key(item)] = item
+	}
+	if err := update(entries); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for key, item := range entries {
+		if item.Expired(now) {
+			delete(entries, key)
+		}
+	}
+	jsonData, err := json.Marshal(lf.encode(entries))
+	if err != nil {
+		return fmt.Errorf("failed to persist %s: %v", lf.name, err)
+	}
+	if err := replaceFile(lf.path, jsonData); err != nil {
+		return fmt.Errorf("failed to persist %s: %v", lf.name, err)
+	}
+	return nil
+}
+
+func (lf listFile[K, T]) remove(key K) error {
+	return lf.update(func(entries map[K]T) error {
+		if item, exist := entries[key]; !exist || item.Expired(time.Now()) {
+			return lf.notFound
+		}
+		delete(entries, key)
+		return nil
+	})
+}
+
+// replaceFile writes data next to path and renames it over path, so readers never see half of it.
+func replaceFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func unexpired[T listed](items []T, now time.Time) []T {
+	active := make([]T, 0, len(items))
+	for _, item := range items {
+		if !item.Expired(now) {
+			active = append(active, item)
+		}
+	}
+	return active
+}
+
+// page returns up to limit items from offset on, all of them when limit is 0.
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return items[offset:end]
+}
@@ -12,39 +12,16 @@ type FileManager struct {
 	mock.Mock
 }
 
-// ListApprovedPhones provides a mock function with given fields:
-func (_m *FileManager) ListApprovedPhones() (map[string]bool, error) {
-	ret := _m.Called()
-
-	var r0 map[string]bool
-	if rf, ok := ret.Get(0).(func() map[string]bool); ok {
-		r0 = rf()
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(map[string]bool)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
 // LoadRulesFromConfig provides a mock function with given fields:
-func (_m *FileManager) LoadRulesFromConfig() ([]models.RuleInfo, error) {
+func (_m *FileManager) LoadRulesFromConfig() (*models.RulesConfig, error) {
 	ret := _m.Called()
 
-	var r0 []models.RuleInfo
-	if rf, ok := ret.Get(0).(func() []models.RuleInfo); ok {
+	var r0 *models.RulesConfig
+	if rf, ok := ret.Get(0).(func() *models.RulesConfig); ok {
 		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.RuleInfo)
+			r0 = ret.Get(0).(*models.RulesConfig)
 		}
 	}
 
@@ -58,20 +35,6 @@ func (_m *FileManager) LoadRulesFromConfig() ([]models.RuleInfo, error) {
 	return r0, r1
 }
 
-// PersistApprovedPhone provides a mock function with given fields: phone
-func (_m *FileManager) PersistApprovedPhone(phone string) error {
-	ret := _m.Called(phone)
-
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(phone)
-	} else {
-		r0 = ret.Error(0)
-	}
-
-	return r0
-}
-
 type mockConstructorTestingTNewFileManager interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,152 @@
+package industry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalid = errors.New("invalid job industry code")
+
+	codePattern = regexp.MustCompile(`^([0-9]+)(?:-([0-9]+))?$`)
+)
+
+type (
+	// Code is a parsed job industry code such as "2-930 - Exterior Plants": division 2, section 930. Codes of a
+	// whole division, e.g. "2 - Site Construction", have no section.
+	Code struct {
+		Code        string `json:"code"`
+		Division    string `json:"division"`
+		Section     string `json:"section,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// Range covers the codes from From to To, both included. A To without a section covers its whole division.
+	Range struct {
+		From Code
+		To   Code
+	}
+
+	// Taxonomy maps every known industry code to its description.
+	Taxonomy map[string]string
+)
+
+// Parse reads a job industry code, with or without its " - description".
+func Parse(raw string) (Code, error) {
+	code, description, _ := strings.Cut(strings.TrimSpace(raw), " - ")
+	parsed, err := parseCode(strings.TrimSpace(code))
+	if err != nil {
+		return Code{}, err
+	}
+	parsed.Description = strings.TrimSpace(description)
+	return parsed, nil
+}
+
+// ParseRange reads a range written as "2-800..2-899".
+func ParseRange(raw string) (Range, error) {
+	from, to, found := strings.Cut(raw, "..")
+	if !found {
+		return Range{}, fmt.Errorf("range %q must be written as from..to", raw)
+	}
+	var r Range
+	var err error
+	if r.From, err = parseCode(from); err != nil {
+		return Range{}, err
+	}
+	if r.To, err = parseCode(to); err != nil {
+		return Range{}, err
+	}
+	if !r.Contains(r.From) {
+		return Range{}, fmt.Errorf("range %q ends before it starts", raw)
+	}
+	return r, nil
+}
+
+// HasPrefix reports whether the code starts with prefix. A division prefix such as "2" only matches division 2,
+// not 21, while "2-9" matches every section of division 2 starting with 9.
+func (c Code) HasPrefix(prefix string) bool {
+	if !strings.Contains(prefix, "-") {
+		return c.Division == prefix
+	}
+	return strings.HasPrefix(c.Code, prefix)
+}
+
+func (r Range) Contains(c Code) bool {
+	if c.before(r.From) {
+		return false
+	}
+	if r.To.Section == "" {
+		return number(c.Division) <= number(r.To.Division)
+	}
+	return !r.To.before(c)
+}
+
+// before orders codes by division and then section, a division on its own comes before its sections.
+func (c Code) before(other Code) bool {
+	division, otherDivision := number(c.Division), number(other.Division)
+	if division != otherDivision {
+		return division < otherDivision
+	}
+	if c.Section == "" || other.Section == "" {
+		return c.Section == "" && other.Section != ""
+	}
+	return number(c.Section) < number(other.Section)
+}
+
+// LoadTaxonomy reads a taxonomy file, e.g. rules/industries.json. A missing file is no taxonomy.
+func LoadTaxonomy(path string) (Taxonomy, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load industry taxonomy: %v", err)
+	}
+
+	var taxonomy Taxonomy
+	if err := json.Unmarshal(data, &taxonomy); err != nil {
+		return nil, fmt.Errorf("invalid industry taxonomy: %v", err)
+	}
+	for code := range taxonomy {
+		if _, err := parseCode(code); err != nil {
+			return nil, fmt.Errorf("invalid industry taxonomy: %v", err)
+		}
+	}
+	return taxonomy, nil
+}
+
+// Known reports whether code is in the taxonomy, every code is known when there is no taxonomy.
+func (t Taxonomy) Known(code string) bool {
+	if t == nil {
+		return true
+	}
+	_, ok := t[code]
+	return ok
+}
+
+// Describe fills in the description of a code that came without one.
+func (t Taxonomy) Describe(c Code) Code {
+	if c.Description == "" {
+		c.Description = t[c.Code]
+	}
+	return c
+}
+
+func parseCode(code string) (Code, error) {
+	match := codePattern.FindStringSubmatch(code)
+	if match == nil {
+		return Code{}, fmt.Errorf("%w: %q", ErrInvalid, code)
+	}
+	return Code{Code: code, Division: match[1], Section: match[2]}, nil
+}
+
+func number(digits string) int {
+	n, _ := strconv.Atoi(digits)
+	return n
+}
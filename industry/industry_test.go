@@ -0,0 +1,113 @@
+package industry
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    Code
+		expectedErr string
+	}{
+		{
+			name:     "section with description",
+			raw:      "2-930 - Exterior Plants",
+			expected: Code{Code: "2-930", Division: "2", Section: "930", Description: "Exterior Plants"},
+		},
+		{
+			name:     "division with description",
+			raw:      "13 - Special Construction",
+			expected: Code{Code: "13", Division: "13", Description: "Special Construction"},
+		},
+		{
+			name:     "code alone",
+			raw:      " 8-050 ",
+			expected: Code{Code: "8-050", Division: "8", Section: "050"},
+		},
+		{
+			name:        "not a code",
+			raw:         "Exterior Plants",
+			expectedErr: `invalid job industry code: "Exterior Plants"`,
+		},
+		{
+			name:        "empty",
+			raw:         "",
+			expectedErr: `invalid job industry code: ""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func Test_Match(t *testing.T) {
+	code, _ := Parse("2-930 - Exterior Plants")
+	division, _ := Parse("2 - Site Construction")
+
+	assert.True(t, code.HasPrefix("2"))
+	assert.True(t, code.HasPrefix("2-9"))
+	assert.False(t, code.HasPrefix("2-8"))
+	assert.False(t, code.HasPrefix("29"))
+	assert.True(t, division.HasPrefix("2"))
+
+	tests := []struct {
+		raw      string
+		code     Code
+		expected bool
+	}{
+		{raw: "2-900..2-999", code: code, expected: true},
+		{raw: "2-100..2-800", code: code, expected: false},
+		{raw: "1..2", code: code, expected: true},
+		{raw: "2-940..3", code: code, expected: false},
+		{raw: "2..2", code: division, expected: true},
+		{raw: "2-100..2-200", code: division, expected: false},
+	}
+	for _, tt := range tests {
+		r, err := ParseRange(tt.raw)
+		if assert.NoError(t, err, tt.raw) {
+			assert.Equal(t, tt.expected, r.Contains(tt.code), tt.raw)
+		}
+	}
+
+	_, err := ParseRange("2-999..2-100")
+	assert.EqualError(t, err, `range "2-999..2-100" ends before it starts`)
+	_, err = ParseRange("2-100")
+	assert.EqualError(t, err, `range "2-100" must be written as from..to`)
+}
+
+func Test_LoadTaxonomy(t *testing.T) {
+	dir := t.TempDir()
+
+	taxonomy, err := LoadTaxonomy(filepath.Join(dir, "missing.json"))
+	assert.NoError(t, err)
+	assert.True(t, taxonomy.Known("99-999"))
+
+	path := filepath.Join(dir, "industries.json")
+	ioutil.WriteFile(path, []byte(`{"2": "Site Construction", "2-930": "Exterior Plants"}`), 0644)
+	taxonomy, err = LoadTaxonomy(path)
+	if assert.NoError(t, err) {
+		assert.True(t, taxonomy.Known("2-930"))
+		assert.False(t, taxonomy.Known("2-931"))
+		code, _ := Parse("2-930")
+		assert.Equal(t, "Exterior Plants", taxonomy.Describe(code).Description)
+	}
+
+	ioutil.WriteFile(path, []byte(`{"Exterior Plants": "2-930"}`), 0644)
+	_, err = LoadTaxonomy(path)
+	assert.EqualError(t, err, `invalid industry taxonomy: invalid job industry code: "Exterior Plants"`)
+}
@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 
 	"github.com/ilivestrong/rules-engine/controllers"
 	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/risk"
 	"github.com/ilivestrong/rules-engine/rules"
 )
 
@@ -22,9 +26,21 @@ const envFile = ".env"
 
 var loadEnv = env.Load
 
+// rulesFileManager is a file manager that can tell where its rules config lives, so it can be watched.
+type rulesFileManager interface {
+	helpers.FileManager
+	RulesConfigPath() string
+	ApprovedPhonesPath() string
+	DenyListPath() string
+	IndustryTaxonomyPath() string
+}
+
 type service struct {
-	Server *http.Server
-	DBConn *pgx.Conn
+	Server      *http.Server
+	AdminServer *http.Server
+	DBConn      *pgx.Conn
+	Products    *rules.Products
+	stopWatch   context.CancelFunc
 }
 
 func run() *service {
@@ -40,55 +56,354 @@ func run() *service {
 	port = fmt.Sprintf(":%s", port)
 	fmt.Println("Listening at: ", port)
 
-	fileManager := helpers.NewFileManager()
+	fileManagers := productFileManagers(helpers.NewFileManager())
 
-	rulesEngine, err := rules.NewRulesEngine(fileManager)
-	if err != nil {
-		fmt.Println(err)
+	var dbConn *pgx.Conn
+	// the database keeps the approved phones and deny list of every product apart, these pick one product's rows
+	var phonesDB func(product string) helpers.ApprovedPhoneStore
+	var denyDB func(product string) helpers.DenyListStore
+	if storeKind("APPROVED_PHONES_STORE") == "postgres" || storeKind("DENY_LIST_STORE") == "postgres" {
+		config := helpers.Config{
+			User:           os.Getenv("DB_USER"),
+			Pass:           os.Getenv("DB_PASSWORD"),
+			Host:           os.Getenv("DB_HOST_NAME"),
+			DBName:         os.Getenv("DB_NAME"),
+			DefaultProduct: defaultProduct(),
+		}
+		rulesDB, err := helpers.NewRulesEngineRepo(context.Background(), &config)
+		if err != nil {
+			fmt.Println(err)
+		} else {
+			dbConn = rulesDB.Conn
+			phonesDB = rulesDB.ApprovedPhones
+			denyDB = rulesDB.DenyList
+		}
 	}
 
-	dbCtx := context.Background()
-
-	config := helpers.Config{
-		User:   os.Getenv("DB_USER"),
-		Pass:   os.Getenv("DB_PASSWORD"),
-		Host:   os.Getenv("DB_HOST_NAME"),
-		DBName: os.Getenv("DB_NAME"),
-	}
-	rulesDB, err := helpers.NewRulesEngineRepo(dbCtx, &config)
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	products, err := loadProducts(watchCtx, fileManagers, approvedPhoneStores(fileManagers, phonesDB), denyListStores(fileManagers, denyDB))
 	if err != nil {
 		fmt.Println(err)
 	}
 
 	mux := http.NewServeMux()
-	mux.Handle("/process", &controllers.CrediCardApprovalHandler{
-		RulesEngine: rulesEngine,
-		FileManager: fileManager,
-		DBManager:   rulesDB,
+	processHandler := &controllers.CrediCardApprovalHandler{
+		Products:    products,
+		ApprovalTTL: approvalTTL(),
+	}
+	mux.Handle("/process", processHandler)
+	mux.Handle("/process/", processHandler)
+	mux.Handle("/process/batch", &controllers.BatchApprovalHandler{
+		Products:     products,
+		MaxBatchSize: envInt("BATCH_MAX_SIZE", controllers.DefaultMaxBatchSize),
+		Workers:      envInt("BATCH_WORKERS", runtime.NumCPU()),
+		ApprovalTTL:  approvalTTL(),
+	})
+	mux.Handle("/process/stream", &controllers.StreamApprovalHandler{
+		Products: products,
+		Workers:  envInt("STREAM_WORKERS", runtime.NumCPU()),
+		Timeout:  httpTimeout(),
+	})
+
+	// admin endpoints change what the rules decide, so they are only served on the admin listener
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/admin/reload", &controllers.RulesReloadHandler{
+		Products: products,
+	})
+	adminMux.Handle("/admin/rules/versions", &controllers.RulesVersionsHandler{
+		Products: products,
 	})
+	adminMux.Handle("/admin/rules/rollback", &controllers.RulesRollbackHandler{
+		Products: products,
+	})
+	adminMux.Handle("/admin/rules/schema", &controllers.RulesSchemaHandler{})
+	phonesHandler := &controllers.ApprovedPhonesHandler{
+		Products: products,
+	}
+	adminMux.Handle("/admin/approved-phones", phonesHandler)
+	adminMux.Handle("/admin/approved-phones/", phonesHandler)
+	denyListHandler := &controllers.DenyListHandler{
+		Products: products,
+	}
+	adminMux.Handle("/admin/deny-list", denyListHandler)
+	adminMux.Handle("/admin/deny-list/", denyListHandler)
 
 	s := &http.Server{
 		Addr:           port,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
+		ReadTimeout:    httpTimeout(),
+		WriteTimeout:   httpTimeout(),
 		MaxHeaderBytes: 1 << 20,
 		Handler:        mux,
 	}
 
+	admin := &http.Server{
+		Addr:           adminAddr(),
+		ReadTimeout:    httpTimeout(),
+		WriteTimeout:   httpTimeout(),
+		MaxHeaderBytes: 1 << 20,
+		Handler:        adminMux,
+	}
+	fmt.Println("Admin listening at: ", admin.Addr)
+
 	go func() {
 		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("error listening on port: %s\n", err)
 		}
 	}()
+	go func() {
+		if err := admin.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("error listening on admin address: %s\n", err)
+		}
+	}()
 
 	return &service{
-		Server: s,
-		DBConn: rulesDB.Conn,
+		Server:      s,
+		AdminServer: admin,
+		DBConn:      dbConn,
+		Products:    products,
+		stopWatch:   stopWatch,
+	}
+}
+
+// productFileManagers finds the file manager of every product. rules/rules.json is the default product
+// (DEFAULT_PRODUCT, "standard" unless set), every folder in rules/products with its own rules.json adds another one.
+func productFileManagers(fileManager rulesFileManager) map[string]rulesFileManager {
+	fileManagers := map[string]rulesFileManager{defaultProduct(): fileManager}
+	dirs, err := helpers.ProductDirs(filepath.Dir(fileManager.RulesConfigPath()))
+	if err != nil {
+		fmt.Println(err)
+	}
+	for product, dir := range dirs {
+		if _, exist := fileManagers[product]; exist {
+			fmt.Printf("product %s is already configured by rules/rules.json, skipping %s\n", product, dir)
+			continue
+		}
+		fileManagers[product] = helpers.NewProductFileManager(dir)
+	}
+	return fileManagers
+}
+
+func defaultProduct() string {
+	product, exist := os.LookupEnv("DEFAULT_PRODUCT")
+	if !exist {
+		return rules.DefaultProduct
+	}
+	return product
+}
+
+// loadProducts builds a rules engine per product, checking the product's approved phone store and deny list. All
+// products share the industry taxonomy next to the default product's rules and the credit risk provider. A product whose rules are invalid is
+// left out.
+func loadProducts(watchCtx context.Context, fileManagers map[string]rulesFileManager, stores map[string]helpers.ApprovedPhoneStore, denyLists map[string]helpers.DenyListStore) (*rules.Products, error) {
+	defaultProduct := defaultProduct()
+	historyDir := os.Getenv("RULES_HISTORY_DIR")
+	interval := rulesWatchInterval()
+	riskProvider := creditRiskProvider()
+	taxonomyPath := ""
+	if fileManager, exist := fileManagers[defaultProduct]; exist {
+		taxonomyPath = fileManager.IndustryTaxonomyPath()
+	}
+	engines := make(map[string]*rules.RulesEngine, len(fileManagers))
+	for product, productFileManager := range fileManagers {
+		productHistoryDir := historyDir
+		if historyDir != "" && product != defaultProduct {
+			productHistoryDir = filepath.Join(historyDir, product)
+		}
+
+		rulesEngine, err := rules.NewRulesEngine(
+			productFileManager,
+			rules.WithHistoryDir(productHistoryDir),
+			rules.WithApprovedPhones(stores[product]),
+			rules.WithDenyList(denyLists[product]),
+			rules.WithIndustryTaxonomy(taxonomyPath),
+			rules.WithRiskProvider(riskProvider),
+		)
+		if err != nil {
+			fmt.Printf("product %s: %v\n", product, err)
+			continue
+		}
+		engines[product] = rulesEngine
+
+		if interval > 0 {
+			go rulesEngine.Watch(watchCtx, productFileManager.RulesConfigPath(), interval)
+		}
+	}
+	return rules.NewProducts(defaultProduct, engines)
+}
+
+// storeKind is where the approved phones or deny list are kept: the value of the env variable name, or else the
+// database when one is configured (DB_HOST_NAME) and the files otherwise.
+func storeKind(name string) string {
+	if kind := os.Getenv(name); kind != "" {
+		return kind
+	}
+	if os.Getenv("DB_HOST_NAME") != "" {
+		return "postgres"
+	}
+	return "file"
+}
+
+// approvedPhoneStores picks where each product keeps its approved phones. APPROVED_PHONES_STORE=postgres keeps them
+// in the approved_phones table by product, "file" uses each product's approved-phone-list.json.
+func approvedPhoneStores(fileManagers map[string]rulesFileManager, db func(product string) helpers.ApprovedPhoneStore) map[string]helpers.ApprovedPhoneStore {
+	kind := storeKind("APPROVED_PHONES_STORE")
+	if kind == "postgres" && db == nil {
+		fmt.Println("APPROVED_PHONES_STORE is postgres but the database is unavailable, using the approved phone files")
+	} else if kind != "file" && kind != "postgres" {
+		fmt.Printf("invalid APPROVED_PHONES_STORE %q, using the approved phone files\n", kind)
+	}
+
+	stores := make(map[string]helpers.ApprovedPhoneStore, len(fileManagers))
+	for product, fileManager := range fileManagers {
+		if kind == "postgres" && db != nil {
+			stores[product] = db(product)
+			continue
+		}
+		stores[product] = helpers.NewFileApprovedPhoneStore(fileManager.ApprovedPhonesPath())
+	}
+	return stores
+}
+
+// denyListStores picks where each product keeps its deny list. DENY_LIST_STORE=postgres keeps it in the
+// denied_values table by product, "file" uses each product's deny-list.json.
+func denyListStores(fileManagers map[string]rulesFileManager, db func(product string) helpers.DenyListStore) map[string]helpers.DenyListStore {
+	kind := storeKind("DENY_LIST_STORE")
+	if kind == "postgres" && db == nil {
+		fmt.Println("DENY_LIST_STORE is postgres but the database is unavailable, using the deny list files")
+	} else if kind != "file" && kind != "postgres" {
+		fmt.Printf("invalid DENY_LIST_STORE %q, using the deny list files\n", kind)
+	}
+
+	stores := make(map[string]helpers.DenyListStore, len(fileManagers))
+	for product, fileManager := range fileManagers {
+		if kind == "postgres" && db != nil {
+			stores[product] = db(product)
+			continue
+		}
+		stores[product] = helpers.NewFileDenyListStore(fileManager.DenyListPath())
+	}
+	return stores
+}
+
+// creditRiskProvider asks the credit bureau at RISK_PROVIDER_URL for the risk of applicants, without it the risk is
+// calculated. RISK_PROVIDER_TIMEOUT limits how long a score may take.
+func creditRiskProvider() risk.RiskProvider {
+	url := os.Getenv("RISK_PROVIDER_URL")
+	if url == "" {
+		return risk.NewCalculatedProvider()
+	}
+	name := os.Getenv("RISK_PROVIDER_NAME")
+	if name == "" {
+		name = "bureau"
+	}
+	timeout := risk.DefaultHTTPTimeout
+	if value, exist := os.LookupEnv("RISK_PROVIDER_TIMEOUT"); exist {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			fmt.Printf("invalid RISK_PROVIDER_TIMEOUT %q, using %s\n", value, timeout)
+		} else {
+			timeout = parsed
+		}
+	}
+	return risk.NewHTTPProvider(name, url, &http.Client{Timeout: timeout})
+}
+
+// rulesWatchInterval reads how often rules.json is checked for changes, 0 disables watching.
+func rulesWatchInterval() time.Duration {
+	value, exist := os.LookupEnv("RULES_WATCH_INTERVAL")
+	if !exist {
+		return 5 * time.Second
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Printf("invalid RULES_WATCH_INTERVAL %q, rules will not be watched\n", value)
+		return 0
+	}
+	return interval
+}
+
+// adminAddr is where the admin endpoints are served, ADMIN_ADDR or only the local machine on port 4334.
+func adminAddr() string {
+	addr, exist := os.LookupEnv("ADMIN_ADDR")
+	if !exist || addr == "" {
+		return "127.0.0.1:4334"
+	}
+	return addr
+}
+
+// httpTimeout limits how long a request may take to read and answer, HTTP_TIMEOUT=0 lifts the limit. Decision
+// streams apply it to the time between lines instead.
+func httpTimeout() time.Duration {
+	value, exist := os.LookupEnv("HTTP_TIMEOUT")
+	if !exist {
+		return 10 * time.Second
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Printf("invalid HTTP_TIMEOUT %q, defaulting to 10s\n", value)
+		return 10 * time.Second
+	}
+	return timeout
+}
+
+// approvalTTL reads how long phones approved by the rules keep bypassing them, by default until removed.
+func approvalTTL() time.Duration {
+	value, exist := os.LookupEnv("APPROVED_PHONE_TTL")
+	if !exist {
+		return 0
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		fmt.Printf("invalid APPROVED_PHONE_TTL %q, approved phones will not expire\n", value)
+		return 0
+	}
+	return ttl
+}
+
+// envInt reads a positive number from the environment, falling back when it is missing or invalid.
+func envInt(name string, fallback int) int {
+	value, exist := os.LookupEnv(name)
+	if !exist {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		fmt.Printf("invalid %s %q, defaulting to %d\n", name, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+// reloadOnSignal reloads the rules of every product each time the process receives SIGHUP.
+func reloadOnSignal(products *rules.Products) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := products.Reload(); err != nil {
+			log.Printf("rules reload rejected, keeping previous rules: %v\n", err)
+			continue
+		}
+		log.Println("rules reloaded on SIGHUP")
 	}
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "simulate":
+			os.Exit(simulate(os.Args[2:], os.Stdout, os.Stderr))
+		case "diff":
+			os.Exit(diffRules(os.Args[2:], os.Stdout, os.Stderr))
+		case "stream":
+			os.Exit(streamDecisions(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		}
+	}
+
 	svc := run()
+	if svc.Products != nil {
+		go reloadOnSignal(svc.Products)
+	}
+
 	quit := make(chan os.Signal, 1)
 	// kill (no param) default send syscall.SIGTERM
 	// kill -2 is syscall.SIGINT
@@ -96,6 +411,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("shutting down server...")
+	svc.stopWatch()
 
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
@@ -106,10 +422,15 @@ func main() {
 	if err := svc.Server.Shutdown(ctx); err != nil {
 		log.Fatal("server forced to shut down")
 	}
+	if err := svc.AdminServer.Shutdown(ctx); err != nil {
+		log.Fatal("admin server forced to shut down")
+	}
 
-	if err := svc.DBConn.Close(ctx); err != nil {
-		log.Fatal("server forced to shut down")
+	if svc.DBConn != nil {
+		if err := svc.DBConn.Close(ctx); err != nil {
+			log.Fatal("server forced to shut down")
+		}
+		log.Println("database connection closed")
 	}
-	log.Println("database connection closed")
 	log.Println("server exiting")
 }
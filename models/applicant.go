@@ -1,5 +1,7 @@
 package models
 
+import "errors"
+
 type (
 	Applicant struct {
 		Income              int    `json:"income"`
@@ -10,3 +12,11 @@ type (
 		PhoneNumber         string `json:"phone_number"`
 	}
 )
+
+// Validate checks the fields an application can't be processed without.
+func (a *Applicant) Validate() error {
+	if a.PoliticallyExposed == nil {
+		return errors.New("politically_exposed is required")
+	}
+	return nil
+}
@@ -1,6 +1,43 @@
 package models
 
-type RuleInfo struct {
-	Name        string         `json:"rule_name"`
-	Constraints map[string]any `json:"constraints"`
+import (
+	"bytes"
+	"encoding/json"
+)
+
+type (
+	RuleInfo struct {
+		Name        string         `json:"rule_name"`
+		Kind        string         `json:"kind,omitempty"`
+		Constraints map[string]any `json:"constraints"`
+		Priority    int            `json:"priority,omitempty"`
+		OnError     string         `json:"on_error,omitempty"`
+		OnFail      string         `json:"on_fail,omitempty"`
+		Rules       []RuleInfo     `json:"rules,omitempty"`
+	}
+
+	RulesConfig struct {
+		Label         string     `json:"label,omitempty"`
+		Mode          string     `json:"mode,omitempty"`
+		RequiredRules []string   `json:"required_rules"`
+		Rules         []RuleInfo `json:"rules"`
+	}
+)
+
+func (rc *RulesConfig) UnmarshalJSON(data []byte) error {
+	// older configs are a plain array of rules without any engine settings
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return json.Unmarshal(trimmed, &rc.Rules)
+	}
+
+	type rulesConfig RulesConfig
+	return json.Unmarshal(data, (*rulesConfig)(rc))
+}
+
+// RuleKind is the kind of rule to build, built-in rules are identified by their name alone.
+func (ri *RuleInfo) RuleKind() string {
+	if ri.Kind != "" {
+		return ri.Kind
+	}
+	return ri.Name
 }
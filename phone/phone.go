@@ -0,0 +1,110 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Area code extractions: the first digit of the number is what the rules have always used, the full NPA
+// (numbering plan area) is the real three digit area code.
+const (
+	FirstDigit Extraction = "first_digit"
+	NPA        Extraction = "npa"
+
+	// DefaultCountryCode is assumed for numbers written without one. Only North American numbers, ten digits after
+	// the country code, are parsed.
+	DefaultCountryCode = "1"
+	nationalDigits     = 10
+	npaDigits          = 3
+)
+
+var ErrInvalid = errors.New("invalid phone number")
+
+type (
+	Extraction string
+
+	// Number is a parsed phone number, whatever way it was written.
+	Number struct {
+		CountryCode string `json:"country_code"`
+		AreaCode    string `json:"area_code"`
+		Subscriber  string `json:"subscriber"`
+	}
+)
+
+// Parse reads a phone number such as "486-356-0375", "(486) 356 0375" or "+1 486 356 0375". The area code is
+// taken from the national number as extraction says, the subscriber number is the rest.
+func Parse(raw string, extraction Extraction) (Number, error) {
+	digits, international, err := digitsOf(raw)
+	if err != nil {
+		return Number{}, err
+	}
+
+	national := digits
+	switch {
+	case len(digits) == len(DefaultCountryCode)+nationalDigits && strings.HasPrefix(digits, DefaultCountryCode):
+		national = digits[len(DefaultCountryCode):]
+	case international:
+		return Number{}, fmt.Errorf("%w: unsupported country code in %q", ErrInvalid, raw)
+	case len(digits) != nationalDigits:
+		return Number{}, fmt.Errorf("%w: %q must have %d digits", ErrInvalid, raw, nationalDigits)
+	}
+
+	if !extraction.Valid() {
+		return Number{}, fmt.Errorf("unknown area code extraction %q", extraction)
+	}
+	areaDigits := extraction.Digits()
+	return Number{
+		CountryCode: DefaultCountryCode,
+		AreaCode:    national[:areaDigits],
+		Subscriber:  national[areaDigits:],
+	}, nil
+}
+
+// Canonical is the number in E.164 form, e.g. "+14863560375".
+func (n Number) Canonical() string {
+	return "+" + n.CountryCode + n.AreaCode + n.Subscriber
+}
+
+// Normalize returns the canonical form of raw, so the same number written differently is looked up the same way.
+// Numbers that can't be parsed are only trimmed.
+func Normalize(raw string) string {
+	number, err := Parse(raw, FirstDigit)
+	if err != nil {
+		return strings.TrimSpace(raw)
+	}
+	return number.Canonical()
+}
+
+func (e Extraction) Valid() bool {
+	return e == FirstDigit || e == NPA
+}
+
+// Digits is how many digits extraction takes for the area code.
+func (e Extraction) Digits() int {
+	if e == NPA {
+		return npaDigits
+	}
+	return 1
+}
+
+// digitsOf strips the separators people write phone numbers with, a leading + marks an international number.
+func digitsOf(raw string) (string, bool, error) {
+	raw = strings.TrimSpace(raw)
+	international := strings.HasPrefix(raw, "+")
+
+	var digits strings.Builder
+	for _, r := range strings.TrimPrefix(raw, "+") {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+		default:
+			return "", false, fmt.Errorf("%w: unexpected %q in %q", ErrInvalid, r, raw)
+		}
+	}
+	if digits.Len() == 0 {
+		return "", false, fmt.Errorf("%w: no digits in %q", ErrInvalid, raw)
+	}
+	return digits.String(), international, nil
+}
@@ -0,0 +1,92 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		extraction  Extraction
+		expected    Number
+		expectedErr string
+	}{
+		{
+			name:       "dashes",
+			raw:        "486-356-0375",
+			extraction: FirstDigit,
+			expected:   Number{CountryCode: "1", AreaCode: "4", Subscriber: "863560375"},
+		},
+		{
+			name:       "parentheses and spaces",
+			raw:        "(486) 356 0375",
+			extraction: NPA,
+			expected:   Number{CountryCode: "1", AreaCode: "486", Subscriber: "3560375"},
+		},
+		{
+			name:       "international",
+			raw:        " +1 486.356.0375 ",
+			extraction: NPA,
+			expected:   Number{CountryCode: "1", AreaCode: "486", Subscriber: "3560375"},
+		},
+		{
+			name:       "country code without plus",
+			raw:        "14863560375",
+			extraction: FirstDigit,
+			expected:   Number{CountryCode: "1", AreaCode: "4", Subscriber: "863560375"},
+		},
+		{
+			name:        "other country",
+			raw:         "+44 20 7946 0958",
+			extraction:  FirstDigit,
+			expectedErr: `invalid phone number: unsupported country code in "+44 20 7946 0958"`,
+		},
+		{
+			name:        "too short",
+			raw:         "356-0375",
+			extraction:  FirstDigit,
+			expectedErr: `invalid phone number: "356-0375" must have 10 digits`,
+		},
+		{
+			name:        "letters",
+			raw:         "486-356-CALL",
+			extraction:  FirstDigit,
+			expectedErr: `invalid phone number: unexpected 'C' in "486-356-CALL"`,
+		},
+		{
+			name:        "empty",
+			raw:         "",
+			extraction:  FirstDigit,
+			expectedErr: `invalid phone number: no digits in ""`,
+		},
+		{
+			name:        "unknown extraction",
+			raw:         "486-356-0375",
+			extraction:  "last_digit",
+			expectedErr: `unknown area code extraction "last_digit"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw, tt.extraction)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, "+14863560375", got.Canonical())
+		})
+	}
+}
+
+func Test_Normalize(t *testing.T) {
+	for _, raw := range []string{"486-356-0375", "(486) 356 0375", "+1 486 356 0375", "+14863560375"} {
+		assert.Equal(t, "+14863560375", Normalize(raw), raw)
+	}
+	assert.Equal(t, "not a phone", Normalize(" not a phone "))
+}
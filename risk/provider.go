@@ -0,0 +1,120 @@
+package risk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/models"
+)
+
+const (
+	BandLow    Band = "LOW"
+	BandMedium Band = "MEDIUM"
+	BandHigh   Band = "HIGH"
+
+	CalculatedProviderName = "calculated"
+	DefaultHTTPTimeout     = 2 * time.Second
+)
+
+type (
+	Band = string
+
+	// Score is an applicant's credit risk as rated by a provider, Band is what the rules decide on.
+	Score struct {
+		Value    float64   `json:"value"`
+		Band     Band      `json:"band"`
+		Provider string    `json:"provider"`
+		ScoredAt time.Time `json:"scored_at"`
+	}
+
+	// RiskProvider rates the credit risk of an applicant, e.g. by asking a credit bureau.
+	RiskProvider interface {
+		Score(ctx context.Context, applicant models.Applicant) (Score, error)
+	}
+
+	calculatedProvider struct{}
+
+	httpProvider struct {
+		name   string
+		url    string
+		client *http.Client
+	}
+
+	// bureauResponse is what an HTTP provider answers with, scored_at is optional.
+	bureauResponse struct {
+		Value    *float64   `json:"value"`
+		Band     Band       `json:"band"`
+		ScoredAt *time.Time `json:"scored_at"`
+	}
+)
+
+// NewCalculatedProvider rates applicants with CalculateCreditRisk, the value is (age + cards) % 3.
+func NewCalculatedProvider() *calculatedProvider {
+	return &calculatedProvider{}
+}
+
+func (cp *calculatedProvider) Score(ctx context.Context, applicant models.Applicant) (Score, error) {
+	return Score{
+		Value:    float64((applicant.Age + applicant.NumberOfCreditCards) % 3),
+		Band:     CalculateCreditRisk(applicant.Age, applicant.NumberOfCreditCards),
+		Provider: CalculatedProviderName,
+		ScoredAt: time.Now().UTC(),
+	}, nil
+}
+
+// NewHTTPProvider rates applicants by posting them as JSON to url, which answers with
+// {"value": 710, "band": "LOW", "scored_at": "..."}. A nil client times out after DefaultHTTPTimeout.
+func NewHTTPProvider(name, url string, client *http.Client) *httpProvider {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+	return &httpProvider{name: name, url: url, client: client}
+}
+
+func (hp *httpProvider) Score(ctx context.Context, applicant models.Applicant) (Score, error) {
+	body, err := json.Marshal(applicant)
+	if err != nil {
+		return Score{}, fmt.Errorf("failed to encode applicant: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hp.url, bytes.NewReader(body))
+	if err != nil {
+		return Score{}, fmt.Errorf("invalid risk provider request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hp.client.Do(req)
+	if err != nil {
+		return Score{}, fmt.Errorf("%s is unavailable: %v", hp.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(resp.Body)
+		return Score{}, fmt.Errorf("%s responded %d: %s", hp.name, resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var scored bureauResponse
+	if err := json.NewDecoder(resp.Body).Decode(&scored); err != nil {
+		return Score{}, fmt.Errorf("invalid %s response: %v", hp.name, err)
+	}
+	if scored.Value == nil {
+		return Score{}, fmt.Errorf("invalid %s response: value is missing", hp.name)
+	}
+	if !ValidBand(scored.Band) {
+		return Score{}, fmt.Errorf("invalid %s response: unknown band %q", hp.name, scored.Band)
+	}
+
+	score := Score{Value: *scored.Value, Band: scored.Band, Provider: hp.name, ScoredAt: time.Now().UTC()}
+	if scored.ScoredAt != nil {
+		score.ScoredAt = *scored.ScoredAt
+	}
+	return score, nil
+}
+
+func ValidBand(band Band) bool {
+	return band == BandLow || band == BandMedium || band == BandHigh
+}
@@ -0,0 +1,106 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CalculatedProvider(t *testing.T) {
+	score, err := NewCalculatedProvider().Score(context.Background(), models.Applicant{Age: 30, NumberOfCreditCards: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), score.Value)
+	assert.Equal(t, BandMedium, score.Band)
+	assert.Equal(t, CalculatedProviderName, score.Provider)
+	assert.False(t, score.ScoredAt.IsZero())
+}
+
+func Test_HTTPProvider(t *testing.T) {
+	scoredAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	// the stub bureau rates applicants by their number of credit cards
+	bureau := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		var applicant models.Applicant
+		if req.Method != http.MethodPost || json.NewDecoder(req.Body).Decode(&applicant) != nil {
+			resp.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch applicant.NumberOfCreditCards {
+		case 0:
+			json.NewEncoder(resp).Encode(map[string]any{"value": 780, "band": "LOW", "scored_at": scoredAt})
+		case 1:
+			json.NewEncoder(resp).Encode(map[string]any{"value": 520, "band": "HIGH"})
+		case 2:
+			json.NewEncoder(resp).Encode(map[string]any{"value": 600, "band": "UNKNOWN"})
+		case 3:
+			time.Sleep(50 * time.Millisecond)
+			json.NewEncoder(resp).Encode(map[string]any{"value": 700, "band": "LOW"})
+		default:
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			resp.Write([]byte("maintenance"))
+		}
+	}))
+	defer bureau.Close()
+
+	provider := NewHTTPProvider("stub-bureau", bureau.URL, &http.Client{Timeout: 20 * time.Millisecond})
+
+	tests := []struct {
+		name          string
+		cards         int
+		expected      Score
+		expectedErr   string
+		expectedNewAt bool
+	}{
+		{
+			name:     "scored with the bureau's timestamp",
+			cards:    0,
+			expected: Score{Value: 780, Band: BandLow, Provider: "stub-bureau", ScoredAt: scoredAt},
+		},
+		{
+			name:          "scored now without one",
+			cards:         1,
+			expected:      Score{Value: 520, Band: BandHigh, Provider: "stub-bureau"},
+			expectedNewAt: true,
+		},
+		{
+			name:        "unknown band",
+			cards:       2,
+			expectedErr: `invalid stub-bureau response: unknown band "UNKNOWN"`,
+		},
+		{
+			name:        "timeout",
+			cards:       3,
+			expectedErr: "stub-bureau is unavailable",
+		},
+		{
+			name:        "bureau error",
+			cards:       4,
+			expectedErr: "stub-bureau responded 503: maintenance",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, err := provider.Score(context.Background(), models.Applicant{NumberOfCreditCards: tt.cards})
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if tt.expectedNewAt {
+				assert.WithinDuration(t, time.Now(), score.ScoredAt, time.Minute)
+				score.ScoredAt = time.Time{}
+			}
+			assert.Equal(t, tt.expected, score)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/ilivestrong/rules-engine/models"
+)
+
+const (
+	RuleCompare = "Compare"
+
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpEqual        = "=="
+	OpNotEqual     = "!="
+	OpIn           = "in"
+	OpNotIn        = "not_in"
+	OpBetween      = "between"
+	OpMatches      = "matches"
+)
+
+type CompareRule struct {
+	config    CompareConstraints
+	field     applicantField
+	predicate func(actual any) bool
+}
+
+func (cr *CompareRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	actual := cr.field.get(&applicant)
+	if actual == nil {
+		return errored(errors.New("applicant value is missing"), cr.config, nil)
+	}
+	return result(cr.predicate(actual), cr.config, actual)
+}
+
+func newCompareRule(constraints map[string]any) (*CompareRule, error) {
+	config, err := decodeConstraints[CompareConstraints](constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	field, ok := applicantFields[config.Field]
+	if !ok {
+		return nil, fmt.Errorf("unknown applicant field %q", config.Field)
+	}
+	if config.Value == nil {
+		return nil, fmt.Errorf("missing value for operator %q", config.Operator)
+	}
+
+	predicate, err := comparePredicate(field.kind, config.Operator, config.Value)
+	if err != nil {
+		return nil, fmt.Errorf("field %q: %v", config.Field, err)
+	}
+
+	return &CompareRule{
+		config:    config,
+		field:     field,
+		predicate: predicate,
+	}, nil
+}
+
+func comparePredicate(kind fieldKind, operator string, value any) (func(actual any) bool, error) {
+	switch operator {
+	case OpGreater, OpGreaterEqual, OpLess, OpLessEqual:
+		if kind != numberField {
+			return nil, fmt.Errorf("operator %q needs a number field, got %s", operator, kind)
+		}
+		bound, ok := toFloat(value)
+		if !ok {
+			return nil, fmt.Errorf("operator %q needs a number value, got %v", operator, value)
+		}
+		return func(actual any) bool {
+			v := actual.(float64)
+			switch operator {
+			case OpGreater:
+				return v > bound
+			case OpGreaterEqual:
+				return v >= bound
+			case OpLess:
+				return v < bound
+			default:
+				return v <= bound
+			}
+		}, nil
+	case OpEqual, OpNotEqual:
+		expected, err := normalizeValue(kind, value)
+		if err != nil {
+			return nil, err
+		}
+		return func(actual any) bool {
+			return (actual == expected) == (operator == OpEqual)
+		}, nil
+	case OpIn, OpNotIn:
+		if kind == boolField {
+			return nil, fmt.Errorf("operator %q is not supported for bool fields", operator)
+		}
+		list, err := toList(value)
+		if err != nil {
+			return nil, fmt.Errorf("operator %q: %v", operator, err)
+		}
+		set := make(map[any]bool, len(list))
+		for _, item := range list {
+			v, err := normalizeValue(kind, item)
+			if err != nil {
+				return nil, err
+			}
+			set[v] = true
+		}
+		return func(actual any) bool {
+			return set[actual] == (operator == OpIn)
+		}, nil
+	case OpBetween:
+		if kind != numberField {
+			return nil, fmt.Errorf("operator %q needs a number field, got %s", operator, kind)
+		}
+		list, err := toList(value)
+		if err != nil || len(list) != 2 {
+			return nil, fmt.Errorf("operator %q needs a [min, max] value, got %v", operator, value)
+		}
+		min, minOk := toFloat(list[0])
+		max, maxOk := toFloat(list[1])
+		if !minOk || !maxOk || min > max {
+			return nil, fmt.Errorf("operator %q needs a [min, max] value, got %v", operator, value)
+		}
+		return func(actual any) bool {
+			v := actual.(float64)
+			return v >= min && v <= max
+		}, nil
+	case OpMatches:
+		if kind != stringField {
+			return nil, fmt.Errorf("operator %q needs a string field, got %s", operator, kind)
+		}
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("operator %q needs a string pattern, got %v", operator, value)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		return func(actual any) bool {
+			return re.MatchString(actual.(string))
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q", operator)
+	}
+}
+
+func normalizeValue(kind fieldKind, value any) (any, error) {
+	switch kind {
+	case numberField:
+		if v, ok := toFloat(value); ok {
+			return v, nil
+		}
+	case stringField:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case boolField:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a %s value, got %v", kind, value)
+}
+
+func toList(value any) ([]any, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("expected a list, got %v", value)
+	}
+
+	list := make([]any, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list, nil
+}
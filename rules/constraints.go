@@ -0,0 +1,227 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Constraint types per rule kind. Tags describe the schema used to validate rules.json and to generate JSON Schema:
+// json is the key, default a JSON literal used when the key is missing, plus optional required, min, max, enum
+// (comma separated) and pattern (for each list item).
+type (
+	MasterConstraints struct {
+		CheckApprovedPhones bool `json:"check_approved_phones" default:"true"`
+	}
+
+	DenyListConstraints struct {
+		Fields []string `json:"fields" default:"[\"phone_number\",\"job_industry_code\"]" pattern:"^(age|income|job_industry_code|number_of_credit_cards|phone_number|politically_exposed)$"`
+	}
+
+	IncomeConstraints struct {
+		MinimumSalary int `json:"minimum_salary" default:"100000" min:"0"`
+	}
+
+	AgeConstraints struct {
+		MinAgeAllowed int `json:"min_age_allowed" default:"18" min:"0" max:"150"`
+	}
+
+	NoOfCreditCardsConstraints struct {
+		MaxCreditCardAllowed int `json:"max_credit_card_allowed" default:"3" min:"0"`
+	}
+
+	PoliticallyExposedConstraints struct {
+		IsExposed bool `json:"is_pp_exposed" default:"true"`
+	}
+
+	PhoneLocationConstraints struct {
+		AllowedAreaCodes []string `json:"allowed_area_codes" default:"[\"0\",\"2\",\"5\",\"8\"]" pattern:"^[0-9]{1,3}$"`
+		AreaCode         string   `json:"area_code" default:"\"first_digit\"" enum:"first_digit,npa"`
+	}
+
+	IndustryConstraints struct {
+		Action   string   `json:"action" default:"\"block\"" enum:"allow,block"`
+		Codes    []string `json:"codes" pattern:"^[0-9]+(-[0-9]+)?$"`
+		Prefixes []string `json:"prefixes" pattern:"^[0-9]+(-[0-9]*)?$"`
+		Ranges   []string `json:"ranges" pattern:"^[0-9]+(-[0-9]+)?\\.\\.[0-9]+(-[0-9]+)?$"`
+	}
+
+	CompareConstraints struct {
+		Field    string `json:"field" required:"true" enum:"age,income,job_industry_code,number_of_credit_cards,phone_number,politically_exposed"`
+		Operator string `json:"operator" required:"true" enum:">,>=,<,<=,==,!=,in,not_in,between,matches"`
+		Value    any    `json:"value" required:"true"`
+	}
+
+	ExpressionConstraints struct {
+		Expression string `json:"expression" required:"true"`
+	}
+
+	GroupConstraints struct{}
+)
+
+// decodeConstraints fills a typed constraint struct from the raw config, starting from its defaults.
+// The raw config is expected to have passed validateConfig already.
+func decodeConstraints[T any](constraints map[string]any) (T, error) {
+	var config T
+	if err := applyDefaults(&config); err != nil {
+		return config, err
+	}
+	if len(constraints) == 0 {
+		return config, nil
+	}
+
+	data, err := json.Marshal(constraints)
+	if err != nil {
+		return config, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return config, fmt.Errorf("invalid constraints: %v", err)
+	}
+	return config, nil
+}
+
+func applyDefaults(config any) error {
+	v := reflect.ValueOf(config).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		def, ok := field.Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal([]byte(def), v.Field(i).Addr().Interface()); err != nil {
+			return fmt.Errorf("invalid default for %s: %v", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func schemaOf(typ reflect.Type) (constraintSchema, error) {
+	schema := make(constraintSchema, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		spec := constraintSpec{
+			required: field.Tag.Get("required") == "true",
+			def:      field.Tag.Get("default"),
+		}
+		switch field.Type.Kind() {
+		case reflect.Int:
+			spec.typ = integerType
+		case reflect.Float64:
+			spec.typ = numberType
+		case reflect.String:
+			spec.typ = stringType
+		case reflect.Bool:
+			spec.typ = boolType
+		case reflect.Slice:
+			spec.typ = stringListType
+		default:
+			spec.typ = anyType
+		}
+		if min, err := strconv.ParseFloat(field.Tag.Get("min"), 64); err == nil {
+			spec.min = bound(min)
+		}
+		if max, err := strconv.ParseFloat(field.Tag.Get("max"), 64); err == nil {
+			spec.max = bound(max)
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			spec.oneOf = strings.Split(enum, ",")
+		}
+		if pattern := field.Tag.Get("pattern"); pattern != "" {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern for %s: %v", field.Name, err)
+			}
+			spec.pattern = re
+		}
+		schema[key] = spec
+	}
+	return schema, nil
+}
+
+// ConstraintsJSONSchema describes the constraints accepted by a rule kind as a JSON Schema object.
+func ConstraintsJSONSchema(kind string) (map[string]any, bool) {
+	entry, ok := lookupKind(kind)
+	if !ok {
+		return nil, false
+	}
+
+	// kinds registered without a constraint type take any object
+	schema := entry.schema
+	if schema == nil {
+		return map[string]any{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"title":   kind + " constraints",
+			"type":    "object",
+		}, true
+	}
+
+	properties := make(map[string]any, len(schema))
+	required := make([]string, 0)
+	for key, spec := range schema {
+		property := map[string]any{}
+		switch spec.typ {
+		case integerType:
+			property["type"] = "integer"
+		case numberType:
+			property["type"] = "number"
+		case stringType:
+			property["type"] = "string"
+		case boolType:
+			property["type"] = "boolean"
+		case stringListType:
+			items := map[string]any{"type": "string"}
+			if spec.pattern != nil {
+				items["pattern"] = spec.pattern.String()
+			}
+			property["type"] = "array"
+			property["items"] = items
+		}
+		if spec.min != nil {
+			property["minimum"] = *spec.min
+		}
+		if spec.max != nil {
+			property["maximum"] = *spec.max
+		}
+		if len(spec.oneOf) > 0 {
+			property["enum"] = spec.oneOf
+		}
+		if spec.def != "" {
+			var def any
+			json.Unmarshal([]byte(spec.def), &def)
+			property["default"] = def
+		}
+		if spec.required {
+			required = append(required, key)
+		}
+		properties[key] = property
+	}
+	sort.Strings(required)
+
+	return map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                kind + " constraints",
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, true
+}
+
+// ConstraintsJSONSchemas returns the JSON Schema of every rule kind, keyed by kind.
+func ConstraintsJSONSchemas() map[string]any {
+	kinds := registeredKinds()
+	schemas := make(map[string]any, len(kinds))
+	for _, kind := range kinds {
+		schemas[kind], _ = ConstraintsJSONSchema(kind)
+	}
+	return schemas
+}
@@ -0,0 +1,108 @@
+package rules
+
+const (
+	OutcomePass  Outcome = "pass"
+	OutcomeFail  Outcome = "fail"
+	OutcomeError Outcome = "error"
+
+	// what a rule error turns into, configured per rule with on_error
+	OnErrorReport     = "error"
+	OnErrorFailClosed = "fail_closed"
+	OnErrorFailOpen   = "fail_open"
+	OnErrorRefer      = "refer"
+
+	// what a failed rule turns into, configured per rule with on_fail
+	OnFailDecline = "decline"
+	OnFailRefer   = "refer"
+)
+
+// statusPrecedence orders the outcomes a decision can end with, a higher one always wins.
+var statusPrecedence = map[Status]int{
+	StatusApproved: 0,
+	StatusReferred: 1,
+	StatusError:    2,
+	StatusDeclined: 3,
+}
+
+type (
+	Outcome = string
+
+	RuleResult struct {
+		Name        string       `json:"rule_name"`
+		Outcome     Outcome      `json:"outcome"`
+		Error       string       `json:"error,omitempty"`
+		Constraints any          `json:"constraints,omitempty"`
+		Actual      any          `json:"actual,omitempty"`
+		Group       string       `json:"group,omitempty"`
+		Children    []RuleResult `json:"rules,omitempty"`
+	}
+
+	Decision struct {
+		Status        Status       `json:"status"`
+		Mode          Mode         `json:"mode"`
+		RulesVersion  string       `json:"rules_version"`
+		RulesLabel    string       `json:"rules_label,omitempty"`
+		Bypassed      bool         `json:"bypassed"`
+		Denied        bool         `json:"denied,omitempty"`
+		DenyReason    string       `json:"deny_reason,omitempty"`
+		FailedRules   []string     `json:"failed_rules,omitempty"`
+		ReferredRules []string     `json:"referred_rules,omitempty"`
+		ErroredRules  []string     `json:"errored_rules,omitempty"`
+		Rules         []RuleResult `json:"rules"`
+	}
+)
+
+func (r RuleResult) Passed() bool {
+	return r.Outcome == OutcomePass
+}
+
+func result(passed bool, constraints any, actual any) RuleResult {
+	outcome := OutcomeFail
+	if passed {
+		outcome = OutcomePass
+	}
+	return RuleResult{
+		Outcome:     outcome,
+		Constraints: constraints,
+		Actual:      actual,
+	}
+}
+
+func errored(err error, constraints any, actual any) RuleResult {
+	return RuleResult{
+		Outcome:     OutcomeError,
+		Error:       err.Error(),
+		Constraints: constraints,
+		Actual:      actual,
+	}
+}
+
+// escalate moves the decision to status unless it already has a stronger one. Bypassed applicants stay approved.
+func (d *Decision) escalate(status Status) {
+	if !d.Bypassed && statusPrecedence[status] > statusPrecedence[d.Status] {
+		d.Status = status
+	}
+}
+
+// recordError adds a rule whose error was not turned into a pass or fail, refer rules send the applicant to review.
+func (d *Decision) recordError(rule *RuleHandler, res RuleResult) {
+	d.ErroredRules = append(d.ErroredRules, res.Name)
+	if rule.onError == OnErrorRefer {
+		d.escalate(StatusReferred)
+		return
+	}
+	d.escalate(StatusError)
+}
+
+// Stricter reports whether status a takes precedence over b, e.g. declined over approved.
+func Stricter(a, b Status) bool {
+	return statusPrecedence[a] > statusPrecedence[b]
+}
+
+func validOnError(policy string) bool {
+	return policy == OnErrorReport || policy == OnErrorFailClosed || policy == OnErrorFailOpen || policy == OnErrorRefer
+}
+
+func validOnFail(policy string) bool {
+	return policy == OnFailDecline || policy == OnFailRefer
+}
@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/industry"
+	"github.com/ilivestrong/rules-engine/models"
+)
+
+const RuleDenyList = "DenyList"
+
+// DenyListRule fails applicants with any of the configured fields on the deny list. Job industry codes are also
+// checked by their code alone, so "2-930" denies "2-930 - Exterior Plants".
+type DenyListRule struct {
+	config   DenyListConstraints
+	denyList helpers.DenyListStore
+}
+
+func (dr *DenyListRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	for _, field := range dr.config.Fields {
+		for _, value := range denyListValues(field, applicantFields[field].get(&applicant)) {
+			denied, err := dr.denyList.Get(ctx, field, value)
+			if errors.Is(err, helpers.ErrNotDenied) {
+				continue
+			}
+			if err != nil {
+				return errored(fmt.Errorf("failed to check deny list: %v", err), dr.config, nil)
+			}
+			return result(false, dr.config, *denied)
+		}
+	}
+	return result(true, dr.config, nil)
+}
+
+func newDenyListRule(constraints map[string]any, deps Dependencies) (*DenyListRule, error) {
+	config, err := decodeConstraints[DenyListConstraints](constraints)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range config.Fields {
+		if !ApplicantField(field) {
+			return nil, fmt.Errorf("unknown applicant field %q", field)
+		}
+	}
+	return &DenyListRule{config: config, denyList: deps.DenyList}, nil
+}
+
+// denyListValues are the forms of an applicant value looked up on the deny list, the way they are written there.
+func denyListValues(field string, actual any) []string {
+	switch v := actual.(type) {
+	case nil:
+		return nil
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case string:
+		values := []string{v}
+		if code, err := industry.Parse(v); err == nil && code.Code != v && field == "job_industry_code" {
+			values = append(values, code.Code)
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// denyReason explains a deny list decline, with the reason it was added for when there is one.
+func denyReason(res RuleResult) string {
+	denied, ok := res.Actual.(helpers.DeniedValue)
+	if !ok {
+		return res.Error // a lookup failure declined by on_error fail_closed
+	}
+	if denied.Reason != "" {
+		return denied.Reason
+	}
+	return fmt.Sprintf("%s %s is on the deny list", denied.Field, denied.Value)
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"regexp"
+	"log"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/industry"
 	"github.com/ilivestrong/rules-engine/models"
+	"github.com/ilivestrong/rules-engine/phone"
 	"github.com/ilivestrong/rules-engine/risk"
 )
 
@@ -22,252 +28,559 @@ const (
 
 	minSalaryConstraint          = "minimum_salary"
 	minAgeConstraint             = "min_age_allowed"
-	maxCreditCardsConstraint     = "max_credit_card_count"
+	maxCreditCardsConstraint     = "max_credit_card_allowed"
 	isExposedConstraint          = "is_pp_exposed"
 	allowedAreaCodesConstraint   = "allowed_area_codes"
 	checkApprovedPhoneConstraint = "check_approved_phones"
 
 	StatusApproved Status = "approved"
 	StatusDeclined Status = "declined"
+	StatusError    Status = "error"
+	StatusReferred Status = "referred"
+
+	ModeShortCircuit Mode = "short_circuit"
+	ModeEvaluateAll  Mode = "evaluate_all"
 )
 
-var allRules = []string{RuleMaster, RuleIncome, RuleAge, RuleNoOfCreditCards, RulePhone, RulePoliticallyExposed}
+// defaultRequiredRules must be present when rules.json doesn't list its own required_rules.
+var defaultRequiredRules = []string{RuleMaster, RuleIncome, RuleAge, RuleNoOfCreditCards, RulePhone, RulePoliticallyExposed}
 
 type (
 	ApprovalRule interface {
-		Execute(ctx context.Context, applicant models.Applicant) bool
+		Execute(ctx context.Context, applicant models.Applicant) RuleResult
 	}
 
 	RuleHandler struct {
-		name string
-		rule ApprovalRule
+		name    string
+		kind    string
+		rule    ApprovalRule
+		onError string
+		onFail  string
 	}
 
 	PreApprovedRule struct{}
 	IncomeRule      struct {
-		constraints map[string]any
+		config IncomeConstraints
 	}
 	AgeRule struct {
-		constraints map[string]any
+		config AgeConstraints
 	}
 	NoOfCreditCardsRule struct {
-		constraints map[string]any
+		config NoOfCreditCardsConstraints
+		risk   risk.RiskProvider
 	}
 	PoliticallyExposedRule struct {
-		constraints map[string]any
+		config PoliticallyExposedConstraints
 	}
 	PhoneLocationRule struct {
-		constraints map[string]any
+		config  PhoneLocationConstraints
+		allowed map[string]bool
 	}
 	MasterRule struct {
-		constraints map[string]any
-		fileManager helpers.FileManager
+		config         MasterConstraints
+		approvedPhones helpers.ApprovedPhoneStore
 	}
 
 	RulesEngine struct {
-		rules      map[string]RuleHandler
+		fileManager    helpers.FileManager
+		approvedPhones helpers.ApprovedPhoneStore
+		denyList       helpers.DenyListStore
+		riskProvider   risk.RiskProvider
+		active         atomic.Value // *ruleSet
+		reloadMu       sync.Mutex
+		history        []*RuleSetVersion
+		historyDir     string
+		taxonomyPath   string
+	}
+
+	ruleSet struct {
+		rules      []RuleHandler
 		masterRule *RuleHandler
+		denyRule   *RuleHandler
+		mode       Mode
+		version    *RuleSetVersion
+		required   []string
+	}
+
+	VerifyOption  func(*verifyOptions)
+	verifyOptions struct {
+		mode Mode
 	}
 
 	Status = string
+	Mode   = string
 )
 
-func (rh *RuleHandler) Handle(ctx context.Context, applicant *models.Applicant) bool {
-	return rh.rule.Execute(ctx, *applicant)
+func (rh *RuleHandler) Handle(ctx context.Context, applicant *models.Applicant) RuleResult {
+	res := rh.rule.Execute(ctx, *applicant)
+	res.Name = rh.name
+
+	// the error message is kept so the decision still shows why the policy kicked in
+	if res.Outcome == OutcomeError {
+		switch rh.onError {
+		case OnErrorFailOpen:
+			res.Outcome = OutcomePass
+		case OnErrorFailClosed:
+			res.Outcome = OutcomeFail
+		}
+	}
+	return res
 }
 
-func (ir *IncomeRule) Execute(ctx context.Context, applicant models.Applicant) bool {
-	minimumSalary := 100000
+func (ir *IncomeRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	return result(applicant.Income > ir.config.MinimumSalary, ir.config, applicant.Income)
+}
 
-	if c, ok := ir.constraints[minSalaryConstraint]; ok {
-		if v, ok := c.(float64); ok {
-			minimumSalary = int(v)
-		}
+func (ar *AgeRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	return result(applicant.Age >= ar.config.MinAgeAllowed, ar.config, applicant.Age)
+}
+
+func (cr *NoOfCreditCardsRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	if applicant.NumberOfCreditCards > cr.config.MaxCreditCardAllowed {
+		// no score can make up for too many cards, don't pay the provider for one
+		return result(false, cr.config, map[string]any{"number_of_credit_cards": applicant.NumberOfCreditCards})
+	}
+	score, err := cr.risk.Score(ctx, applicant)
+	if err != nil {
+		return errored(fmt.Errorf("failed to score credit risk: %v", err), cr.config, applicant.NumberOfCreditCards)
 	}
-	return applicant.Income > minimumSalary
+	return result(
+		score.Band == risk.BandLow,
+		cr.config,
+		map[string]any{"number_of_credit_cards": applicant.NumberOfCreditCards, "credit_risk": score.Band, "risk_score": score},
+	)
 }
 
-func (ar *AgeRule) Execute(ctx context.Context, applicant models.Applicant) bool {
-	minimumAgeRequired := 18
+func (per *PoliticallyExposedRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	if applicant.PoliticallyExposed == nil {
+		return errored(errors.New("politically_exposed is missing"), per.config, nil)
+	}
+	return result(*applicant.PoliticallyExposed == per.config.IsExposed, per.config, *applicant.PoliticallyExposed)
+}
 
-	if c, ok := ar.constraints[minAgeConstraint]; ok {
-		if v, ok := c.(float64); ok {
-			minimumAgeRequired = int(v)
-		}
+func (plr *PhoneLocationRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	number, err := phone.Parse(applicant.PhoneNumber, phone.Extraction(plr.config.AreaCode))
+	if err != nil {
+		// a number without an area code can't be in an allowed area
+		return result(false, plr.config, map[string]any{"phone_number": applicant.PhoneNumber, "error": err.Error()})
 	}
+	return result(
+		plr.allowed[number.AreaCode],
+		plr.config,
+		map[string]any{"phone_number": number.Canonical(), "area_code": number.AreaCode},
+	)
+}
 
-	return applicant.Age >= minimumAgeRequired
+func (bpr *MasterRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	if bpr.config.CheckApprovedPhones {
+		_, err := bpr.approvedPhones.Get(ctx, phone.Normalize(applicant.PhoneNumber))
+		if err == nil {
+			return result(true, bpr.config, applicant.PhoneNumber)
+		}
+		if !errors.Is(err, helpers.ErrPhoneNotFound) {
+			return errored(fmt.Errorf("failed to check approved phones: %v", err), bpr.config, applicant.PhoneNumber)
+		}
+	}
+	return result(false, bpr.config, applicant.PhoneNumber) // don't bypass, execute child rules
 }
 
-func (cr *NoOfCreditCardsRule) Execute(ctx context.Context, applicant models.Applicant) bool {
-	maxCreditCardAllowed := 3
+func newPhoneLocationRule(constraints map[string]any) (*PhoneLocationRule, error) {
+	config, err := decodeConstraints[PhoneLocationConstraints](constraints)
+	if err != nil {
+		return nil, err
+	}
 
-	if c, ok := cr.constraints[maxCreditCardsConstraint]; ok {
-		if v, ok := c.(float64); ok {
-			maxCreditCardAllowed = int(v)
+	extraction := phone.Extraction(config.AreaCode)
+	allowed := make(map[string]bool, len(config.AllowedAreaCodes))
+	for _, code := range config.AllowedAreaCodes {
+		if len(code) != extraction.Digits() {
+			return nil, fmt.Errorf("area code %q must have %d digit(s) with %s extraction", code, extraction.Digits(), extraction)
 		}
+		allowed[code] = true
 	}
+	return &PhoneLocationRule{config: config, allowed: allowed}, nil
+}
 
-	creditRisk := risk.CalculateCreditRisk(applicant.Age, applicant.NumberOfCreditCards)
-	return applicant.NumberOfCreditCards <= maxCreditCardAllowed && creditRisk == "LOW"
+// addRuleHandler sets the DenyList and Master rules apart by their kind, whatever they are named.
+func (rs *ruleSet) addRuleHandler(handler RuleHandler) {
+	switch handler.kind {
+	case RuleMaster:
+		rs.masterRule = &handler
+	case RuleDenyList:
+		rs.denyRule = &handler
+	default:
+		rs.rules = append(rs.rules, handler)
+	}
 }
 
-func (per *PoliticallyExposedRule) Execute(ctx context.Context, applicant models.Applicant) bool {
-	politicallyExposed := true
+func newRuleHandler(ruleInfo models.RuleInfo, rule ApprovalRule) (RuleHandler, error) {
+	onError := ruleInfo.OnError
+	if onError == "" {
+		onError = OnErrorReport
+		// an approved phones lookup failure just means no bypass, all other rules still run
+		// a deny list lookup failure can't clear the applicant, so it goes to manual review
+		switch ruleInfo.RuleKind() {
+		case RuleMaster:
+			onError = OnErrorFailClosed
+		case RuleDenyList:
+			onError = OnErrorRefer
+		}
+	}
+	if !validOnError(onError) {
+		return RuleHandler{}, fmt.Errorf("invalid on_error policy %q", onError)
+	}
 
-	if c, ok := per.constraints[isExposedConstraint]; ok {
-		politicallyExposed = c.(bool)
+	onFail := ruleInfo.OnFail
+	if onFail == "" {
+		onFail = OnFailDecline
+	}
+	if !validOnFail(onFail) {
+		return RuleHandler{}, fmt.Errorf("invalid on_fail policy %q", onFail)
 	}
 
-	return *applicant.PoliticallyExposed == politicallyExposed
+	return RuleHandler{
+		name:    ruleInfo.Name,
+		kind:    ruleInfo.RuleKind(),
+		rule:    rule,
+		onError: onError,
+		onFail:  onFail,
+	}, nil
 }
 
-func (plr *PhoneLocationRule) Execute(ctx context.Context, applicant models.Applicant) bool {
-	areaCodes := []string{"0", "2", "5", "8"}
+// WithMode overrides the engine's configured evaluation mode for a single Verify call.
+func WithMode(mode Mode) VerifyOption {
+	return func(vo *verifyOptions) {
+		vo.mode = mode
+	}
+}
 
-	if c, ok := plr.constraints[allowedAreaCodesConstraint]; ok {
-		var codes []any
-		if codes, ok = c.([]any); !ok {
-			fmt.Println("invaid area codes from config")
-			return false
-		}
+func ValidMode(mode Mode) bool {
+	return mode == ModeShortCircuit || mode == ModeEvaluateAll
+}
+
+func (re *RulesEngine) current() *ruleSet {
+	return re.active.Load().(*ruleSet)
+}
+
+func (re *RulesEngine) Verify(ctx context.Context, applicant *models.Applicant, opts ...VerifyOption) *Decision {
+	// load the active rule set once, so a concurrent reload never mixes two versions in one decision
+	rs := re.current()
+
+	options := verifyOptions{mode: rs.mode}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	evaluateAll := options.mode == ModeEvaluateAll
+	ctx = withMode(ctx, options.mode)
+
+	decision := &Decision{
+		Status:       StatusApproved,
+		Mode:         options.mode,
+		RulesVersion: rs.version.ID,
+		RulesLabel:   rs.version.Label,
+	}
 
-		strCodes := make([]string, len(codes))
-		for i, v := range codes {
-			strCodes[i] = v.(string)
+	// denied applicants are declined right away, even in evaluate_all and before any bypass
+	if rs.denyRule != nil {
+		deny := rs.denyRule.Handle(ctx, applicant)
+		decision.Rules = append(decision.Rules, deny)
+		switch {
+		case deny.Outcome == OutcomeFail:
+			decision.Denied = true
+			decision.DenyReason = denyReason(deny)
+			decision.FailedRules = append(decision.FailedRules, deny.Name)
+			decision.escalate(StatusDeclined)
+			return decision
+		case deny.Outcome == OutcomeError:
+			decision.recordError(rs.denyRule, deny)
 		}
-		areaCodes = strCodes
 	}
 
-	pattern := fmt.Sprintf("^[%s]", strings.Join(areaCodes, ""))
-	matched, err := regexp.MatchString(pattern, applicant.PhoneNumber)
-	if err != nil {
-		return false
+	if rs.masterRule != nil {
+		master := rs.masterRule.Handle(ctx, applicant)
+		decision.Rules = append(decision.Rules, master)
+		if master.Outcome == OutcomeError {
+			decision.recordError(rs.masterRule, master)
+		}
+		if master.Passed() {
+			decision.Bypassed = true
+			if !evaluateAll {
+				return decision
+			}
+		}
 	}
 
-	return matched
+	for i := range rs.rules {
+		rule := &rs.rules[i]
+		res := rule.Handle(ctx, applicant)
+		decision.Rules = append(decision.Rules, res)
+
+		// errors and referrals keep going, a later failure still makes this a clear decline
+		switch {
+		case res.Outcome == OutcomePass:
+			continue
+		case res.Outcome == OutcomeError:
+			decision.recordError(rule, res)
+			continue
+		case rule.onFail == OnFailRefer:
+			decision.ReferredRules = append(decision.ReferredRules, res.Name)
+			decision.escalate(StatusReferred)
+			continue
+		}
+
+		decision.FailedRules = append(decision.FailedRules, res.Name)
+		decision.escalate(StatusDeclined)
+		if !evaluateAll {
+			return decision
+		}
+	}
+	return decision
 }
 
-func (bpr *MasterRule) Execute(ctx context.Context, applicant models.Applicant) bool {
-	bypassIfPhoneIsApproved := true
+func createRule(ruleInfo models.RuleInfo, deps Dependencies) (ApprovalRule, error) {
+	kind := ruleInfo.RuleKind()
+	if isGroup(kind) {
+		return newGroupRule(ruleInfo, deps)
+	}
+
+	entry, ok := lookupKind(kind)
+	if !ok {
+		return nil, fmt.Errorf("unknown rule kind %q", kind)
+	}
+	return entry.factory(ruleInfo.Constraints, deps)
+}
 
-	if c, ok := bpr.constraints[checkApprovedPhoneConstraint]; ok {
-		if v, ok := c.(bool); ok {
-			bypassIfPhoneIsApproved = v
+func buildRuleSet(config *models.RulesConfig, deps Dependencies) (*ruleSet, error) {
+	mode := ModeShortCircuit
+	if config.Mode != "" {
+		if !ValidMode(config.Mode) {
+			return nil, fmt.Errorf("invalid evaluation mode %q, please check rules.json", config.Mode)
 		}
+		mode = config.Mode
 	}
 
-	if bypassIfPhoneIsApproved {
-		approvedPhones, err := bpr.fileManager.ListApprovedPhones()
+	ruleInfos := config.Rules
+	if len(ruleInfos) == 0 {
+		return nil, errors.New("no rules found, please check rules.json")
+	}
+
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+
+	// rules run in config order unless a priority is given, lower priorities run first
+	ordered := append([]models.RuleInfo(nil), ruleInfos...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority < ordered[j].Priority
+	})
+
+	version, err := newVersion(config)
+	if err != nil {
+		return nil, err
+	}
+
+	required := config.RequiredRules
+	if required == nil {
+		required = defaultRequiredRules
+	}
+
+	rs := &ruleSet{mode: mode, version: version, required: required}
+
+	for _, ruleInfo := range ordered {
+		rule, err := createRule(ruleInfo, deps)
 		if err != nil {
-			fmt.Println("something went wrong with loading approved phones json, will revalidate all rules")
-			return false // something went wrong with approved phones checking, let's revalidate all rules again then
+			return nil, fmt.Errorf("invalid rule %s: %v", ruleInfo.Name, err)
 		}
-
-		if _, ok := approvedPhones[applicant.PhoneNumber]; ok {
-			fmt.Println("applican't phone number is pre-approved, skipping all child rules")
-			return true
+		handler, err := newRuleHandler(ruleInfo, rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %s: %v", ruleInfo.Name, err)
 		}
+		rs.addRuleHandler(handler)
 	}
-	return false // don't bypass, execute child rules
-}
 
-func (re *RulesEngine) addRuleHandler(rule ApprovalRule, name string) {
-	handler := &RuleHandler{
-		rule: rule,
-		name: name,
+	if missing := rs.missingRules(); len(missing) > 0 {
+		return nil, fmt.Errorf("missing required rules %s, please check rules.json", strings.Join(missing, ", "))
 	}
-	if name == RuleMaster {
-		re.masterRule = &RuleHandler{
-			rule: rule,
-			name: name,
-		}
-	} else {
-		re.rules[name] = *handler
+	return rs, nil
+}
+
+// WithApprovedPhones sets where approved phones are looked up, without it no phone is approved.
+func WithApprovedPhones(store helpers.ApprovedPhoneStore) EngineOption {
+	return func(re *RulesEngine) {
+		re.approvedPhones = store
 	}
 }
 
-func (re *RulesEngine) Verify(ctx context.Context, applicant *models.Applicant) Status {
-	if re.masterRule.Handle(ctx, applicant) {
-		return StatusApproved
+// WithDenyList sets where denied applicant values are looked up, without it nothing is denied.
+func WithDenyList(store helpers.DenyListStore) EngineOption {
+	return func(re *RulesEngine) {
+		re.denyList = store
 	}
+}
 
-	for _, rule := range re.rules {
-		if ok := rule.Handle(ctx, applicant); !ok {
-			fmt.Printf("DEBUG:: failed the rule: %s", rule.name)
-			return StatusDeclined
-		}
+// WithIndustryTaxonomy loads the known job industry codes from path each time the rules are loaded, without it any
+// code is accepted.
+func WithIndustryTaxonomy(path string) EngineOption {
+	return func(re *RulesEngine) {
+		re.taxonomyPath = path
 	}
-	return StatusApproved
 }
 
-func createRule(ruleInfo models.RuleInfo, fileMgr helpers.FileManager) (ApprovalRule, error) {
-	switch ruleInfo.Name {
-	case RuleIncome:
-		return &IncomeRule{
-			constraints: ruleInfo.Constraints,
-		}, nil
-	case RuleAge:
-		return &AgeRule{
-			constraints: ruleInfo.Constraints,
-		}, nil
-	case RuleNoOfCreditCards:
-		return &NoOfCreditCardsRule{
-			constraints: ruleInfo.Constraints,
-		}, nil
-	case RulePoliticallyExposed:
-		return &PoliticallyExposedRule{
-			constraints: ruleInfo.Constraints,
-		}, nil
-	case RulePhone:
-		return &PhoneLocationRule{
-			constraints: ruleInfo.Constraints,
-		}, nil
-	case RuleMaster:
-		return &MasterRule{
-			constraints: ruleInfo.Constraints,
-			fileManager: fileMgr,
-		}, nil
-	default:
-		fmt.Printf("Unknown rule in config: %s, skipping...\n", ruleInfo.Name)
-		return nil, errors.New("unknow rule")
+// WithRiskProvider sets who rates the credit risk of applicants, without it risk.CalculateCreditRisk does.
+func WithRiskProvider(provider risk.RiskProvider) EngineOption {
+	return func(re *RulesEngine) {
+		re.riskProvider = provider
 	}
 }
 
-func NewRulesEngine(fileManager helpers.FileManager) (*RulesEngine, error) {
-	ruleInfos, err := fileManager.LoadRulesFromConfig()
+func NewRulesEngine(fileManager helpers.FileManager, opts ...EngineOption) (*RulesEngine, error) {
+	config, err := fileManager.LoadRulesFromConfig()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(ruleInfos) == 0 {
-		return nil, errors.New("no rules found, please check rules.json")
+	rulesEngine := &RulesEngine{fileManager: fileManager}
+	for _, opt := range opts {
+		opt(rulesEngine)
+	}
+	if rulesEngine.approvedPhones == nil {
+		rulesEngine.approvedPhones = helpers.NewMemoryApprovedPhoneStore()
+	}
+	if rulesEngine.denyList == nil {
+		rulesEngine.denyList = helpers.NewMemoryDenyListStore()
+	}
+	if rulesEngine.riskProvider == nil {
+		rulesEngine.riskProvider = risk.NewCalculatedProvider()
 	}
 
-	rulesEngine := RulesEngine{
-		rules: make(map[string]RuleHandler),
+	deps, err := rulesEngine.dependencies()
+	if err != nil {
+		return nil, err
 	}
+	rs, err := buildRuleSet(config, deps)
+	if err != nil {
+		return nil, err
+	}
+	rulesEngine.loadHistory()
+	rulesEngine.activate(rs)
+	return rulesEngine, nil
+}
 
-	for _, ruleInfo := range ruleInfos {
-		rule, err := createRule(ruleInfo, fileManager)
-		if err != nil {
-			fmt.Println("createRule:: ", err)
-			continue
+// Reload loads the rules config again and swaps it in for new Verify calls. The config is fully validated
+// first and an invalid one is rejected, keeping the active rules.
+func (re *RulesEngine) Reload() error {
+	re.reloadMu.Lock()
+	defer re.reloadMu.Unlock()
+
+	config, err := re.fileManager.LoadRulesFromConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load rules: %v", err)
+	}
+
+	deps, err := re.dependencies()
+	if err != nil {
+		return err
+	}
+	rs, err := buildRuleSet(config, deps)
+	if err != nil {
+		return err
+	}
+
+	re.activate(rs)
+	return nil
+}
+
+// ApprovedPhones is the store the Master rule checks, approvals are saved to it too.
+func (re *RulesEngine) ApprovedPhones() helpers.ApprovedPhoneStore {
+	return re.approvedPhones
+}
+
+// DenyList is the store the DenyList rule checks.
+func (re *RulesEngine) DenyList() helpers.DenyListStore {
+	return re.denyList
+}
+
+// dependencies are handed to the rules being built, the industry taxonomy is read again so it changes with the rules.
+func (re *RulesEngine) dependencies() (Dependencies, error) {
+	deps := Dependencies{FileManager: re.fileManager, ApprovedPhones: re.approvedPhones, DenyList: re.denyList, Risk: re.riskProvider}
+	if re.taxonomyPath == "" {
+		return deps, nil
+	}
+	taxonomy, err := industry.LoadTaxonomy(re.taxonomyPath)
+	if err != nil {
+		return deps, err
+	}
+	deps.Industries = taxonomy
+	return deps, nil
+}
+
+// Watch reloads the rules whenever the config file at path changes, until ctx is cancelled.
+func (re *RulesEngine) Watch(ctx context.Context, path string, interval time.Duration) {
+	helpers.WatchFile(ctx, path, interval, func() {
+		if err := re.Reload(); err != nil {
+			log.Printf("rules reload rejected, keeping previous rules: %v\n", err)
+			return
 		}
-		rulesEngine.addRuleHandler(rule, ruleInfo.Name)
+		log.Printf("rules reloaded from %s, active version %s\n", path, re.Version().ID)
+	})
+}
+
+// RuleNames lists the top level rules of the active rule set in evaluation order, DenyList and Master first.
+func (re *RulesEngine) RuleNames() []string {
+	rs := re.current()
+	names := make([]string, 0, len(rs.rules)+2)
+	if rs.denyRule != nil {
+		names = append(names, rs.denyRule.name)
+	}
+	if rs.masterRule != nil {
+		names = append(names, rs.masterRule.name)
+	}
+	for _, rule := range rs.rules {
+		names = append(names, rule.name)
+	}
+	return names
+}
+
+// BypassRuleName is the name of the active rule set's Master rule, whatever it is called, or "" without one.
+func (re *RulesEngine) BypassRuleName() string {
+	if rs := re.current(); rs.masterRule != nil {
+		return rs.masterRule.name
 	}
+	return ""
+}
 
-	if !EngineRulesValid(&rulesEngine) {
-		return nil, errors.New("missing rules, please check rules.json")
+// AllRuleNames lists the rules like RuleNames, with the rules nested in a group right after the group.
+func (re *RulesEngine) AllRuleNames() []string {
+	rs := re.current()
+	names := make([]string, 0, len(rs.rules)+2)
+	if rs.denyRule != nil {
+		names = append(names, rs.denyRule.name)
 	}
-	return &rulesEngine, nil
+	if rs.masterRule != nil {
+		names = append(names, rs.masterRule.name)
+	}
+	return appendRuleNames(names, rs.rules)
 }
 
 func EngineRulesValid(engine *RulesEngine) bool {
-	for _, rule := range allRules {
-		if _, ok := engine.rules[rule]; !ok && rule != RuleMaster {
-			return false
+	return len(engine.current().missingRules()) == 0
+}
+
+func (rs *ruleSet) missingRules() []string {
+	loaded := make(map[string]bool, len(rs.rules))
+	collectRuleNames(rs.rules, loaded)
+	if rs.masterRule != nil {
+		loaded[rs.masterRule.name] = true
+	}
+	if rs.denyRule != nil {
+		loaded[rs.denyRule.name] = true
+	}
+
+	var missing []string
+	for _, rule := range rs.required {
+		if !loaded[rule] {
+			missing = append(missing, rule)
 		}
 	}
-	return engine.masterRule != nil
+	return missing
 }
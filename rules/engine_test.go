@@ -3,11 +3,17 @@ package rules
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ilivestrong/rules-engine/helpers"
 	"github.com/ilivestrong/rules-engine/helpers/mocks"
+	"github.com/ilivestrong/rules-engine/industry"
 	"github.com/ilivestrong/rules-engine/models"
+	"github.com/ilivestrong/rules-engine/risk"
+	riskmocks "github.com/ilivestrong/rules-engine/risk/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -89,7 +95,7 @@ func Test_NewRulesEngine(t *testing.T) {
 				config: "rules_config",
 			},
 			mocks: func(df *depFields) {
-				df.FileManager.On("LoadRulesFromConfig").Return([]models.RuleInfo{}, nil)
+				df.FileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{}, nil)
 			},
 			wantErr:     true,
 			expectedErr: fmt.Errorf("no rules found, please check rules.json"),
@@ -100,15 +106,28 @@ func Test_NewRulesEngine(t *testing.T) {
 				config: "rules_config",
 			},
 			mocks: func(df *depFields) {
-				df.FileManager.On("LoadRulesFromConfig").Return([]models.RuleInfo{
-					{
-						Name:        RuleIncome,
-						Constraints: map[string]any{},
+				df.FileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+					Rules: []models.RuleInfo{
+						{
+							Name:        RuleIncome,
+							Constraints: map[string]any{},
+						},
 					},
 				}, nil)
 			},
 			wantErr:     true,
-			expectedErr: fmt.Errorf("missing rules, please check rules.json"),
+			expectedErr: fmt.Errorf("missing required rules Master, Age, NoOfCreditCards, PhoneLocation, PoliticallyExposed, please check rules.json"),
+		},
+		{
+			name: "invalid evaluation mode",
+			args: args{
+				config: "rules_config",
+			},
+			mocks: func(df *depFields) {
+				df.FileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Mode: "sometimes", Rules: mockRules}, nil)
+			},
+			wantErr:     true,
+			expectedErr: fmt.Errorf("invalid evaluation mode \"sometimes\", please check rules.json"),
 		},
 		{
 			name: "valid rules loaded from config",
@@ -116,7 +135,7 @@ func Test_NewRulesEngine(t *testing.T) {
 				config: "rules_config",
 			},
 			mocks: func(df *depFields) {
-				df.FileManager.On("LoadRulesFromConfig").Return(mockRules, nil)
+				df.FileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: mockRules}, nil)
 			},
 			wantErr: false,
 		},
@@ -154,11 +173,10 @@ func Test_RulesEngine_Verify(t *testing.T) {
 	}
 
 	approvedPhoneNumber := "501-324-0507"
-	oneApprovedPhone := make(helpers.ApprovedPhones)
-	oneApprovedPhone[approvedPhoneNumber] = true
+	oneApprovedPhone := helpers.NewMemoryApprovedPhoneStore(helpers.ApprovedPhone{Phone: approvedPhoneNumber})
 	PPE := false
 	PPEYES := true
-	fileManager := helpers.NewFileManager()
+	fileManager := helpers.NewProductFileManager("../rules")
 	rules, _ := fileManager.LoadRulesFromConfig()
 
 	tests := []struct {
@@ -184,7 +202,6 @@ func Test_RulesEngine_Verify(t *testing.T) {
 			},
 			mocks: func(df *depFields) {
 				df.FileManager.On("LoadRulesFromConfig").Return(rules, nil)
-				df.FileManager.On("ListApprovedPhones").Return(oneApprovedPhone, nil)
 			},
 			wantErr:  false,
 			expected: StatusApproved,
@@ -204,7 +221,6 @@ func Test_RulesEngine_Verify(t *testing.T) {
 			},
 			mocks: func(df *depFields) {
 				df.FileManager.On("LoadRulesFromConfig", mock.Anything).Return(rules, nil)
-				df.FileManager.On("ListApprovedPhones", mock.Anything).Return(oneApprovedPhone, nil)
 			},
 			wantErr:  false,
 			expected: StatusDeclined,
@@ -224,7 +240,6 @@ func Test_RulesEngine_Verify(t *testing.T) {
 			},
 			mocks: func(df *depFields) {
 				df.FileManager.On("LoadRulesFromConfig", mock.Anything).Return(rules, nil)
-				df.FileManager.On("ListApprovedPhones", mock.Anything).Return(oneApprovedPhone, nil)
 			},
 			wantErr:  false,
 			expected: StatusDeclined,
@@ -244,7 +259,6 @@ func Test_RulesEngine_Verify(t *testing.T) {
 			},
 			mocks: func(df *depFields) {
 				df.FileManager.On("LoadRulesFromConfig", mock.Anything).Return(rules, nil)
-				df.FileManager.On("ListApprovedPhones", mock.Anything).Return(oneApprovedPhone, nil)
 			},
 			wantErr:  false,
 			expected: StatusDeclined,
@@ -264,7 +278,6 @@ func Test_RulesEngine_Verify(t *testing.T) {
 			},
 			mocks: func(df *depFields) {
 				df.FileManager.On("LoadRulesFromConfig", mock.Anything).Return(rules, nil)
-				df.FileManager.On("ListApprovedPhones", mock.Anything).Return(oneApprovedPhone, nil)
 			},
 			wantErr:  false,
 			expected: StatusDeclined,
@@ -284,7 +297,6 @@ func Test_RulesEngine_Verify(t *testing.T) {
 			},
 			mocks: func(df *depFields) {
 				df.FileManager.On("LoadRulesFromConfig", mock.Anything).Return(rules, nil)
-				df.FileManager.On("ListApprovedPhones", mock.Anything).Return(oneApprovedPhone, nil)
 			},
 			wantErr:  false,
 			expected: StatusDeclined,
@@ -304,7 +316,6 @@ func Test_RulesEngine_Verify(t *testing.T) {
 			},
 			mocks: func(df *depFields) {
 				df.FileManager.On("LoadRulesFromConfig", mock.Anything).Return(rules, nil)
-				df.FileManager.On("ListApprovedPhones", mock.Anything).Return(oneApprovedPhone, nil)
 			},
 			wantErr:  false,
 			expected: StatusDeclined,
@@ -324,7 +335,6 @@ func Test_RulesEngine_Verify(t *testing.T) {
 			},
 			mocks: func(df *depFields) {
 				df.FileManager.On("LoadRulesFromConfig", mock.Anything).Return(rules, nil)
-				df.FileManager.On("ListApprovedPhones", mock.Anything).Return(oneApprovedPhone, nil)
 			},
 			wantErr:  false,
 			expected: StatusApproved,
@@ -338,10 +348,1538 @@ func Test_RulesEngine_Verify(t *testing.T) {
 			}
 			tt.mocks(df)
 
-			engine, _ := NewRulesEngine(df.FileManager)
-			status := engine.Verify(context.Background(), tt.args.applicant)
+			engine, _ := NewRulesEngine(df.FileManager, WithApprovedPhones(oneApprovedPhone))
+			decision := engine.Verify(context.Background(), tt.args.applicant)
+
+			assert.Equal(t, tt.expected, decision.Status)
+		})
+	}
+}
+
+func Test_RulesEngine_Verify_Decision(t *testing.T) {
+	approvedPhoneNumber := "501-324-0507"
+	oneApprovedPhone := helpers.NewMemoryApprovedPhoneStore(helpers.ApprovedPhone{Phone: approvedPhoneNumber})
+	PPE := false
+
+	t.Run("bypass is reported with the master rule only", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: mockRules}, nil)
+
+		engine, _ := NewRulesEngine(fileManager, WithApprovedPhones(oneApprovedPhone))
+		decision := engine.Verify(context.Background(), &models.Applicant{
+			Age:                1,
+			PoliticallyExposed: &PPE,
+			PhoneNumber:        approvedPhoneNumber,
+		})
+
+		assert.Equal(t, StatusApproved, decision.Status)
+		assert.True(t, decision.Bypassed)
+		if assert.Len(t, decision.Rules, 1) {
+			assert.Equal(t, RuleMaster, decision.Rules[0].Name)
+			assert.True(t, decision.Rules[0].Passed())
+		}
+	})
+
+	t.Run("phones approved at runtime bypass", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: mockRules}, nil)
+		store := helpers.NewMemoryApprovedPhoneStore()
+		applicant := &models.Applicant{Age: 1, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507"}
+
+		engine, _ := NewRulesEngine(fileManager, WithApprovedPhones(store))
+		assert.Equal(t, StatusDeclined, engine.Verify(context.Background(), applicant).Status)
+
+		assert.NoError(t, engine.ApprovedPhones().Add(context.Background(), helpers.ApprovedPhone{Phone: applicant.PhoneNumber}))
+		assert.True(t, engine.Verify(context.Background(), applicant).Bypassed)
+	})
+
+	t.Run("expired phones do not bypass", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: mockRules}, nil)
+		expiredAt := time.Now().Add(-time.Hour)
+		store := helpers.NewMemoryApprovedPhoneStore(helpers.ApprovedPhone{
+			Phone:   approvedPhoneNumber,
+			Listing: helpers.Listing{Source: helpers.SourceAutoApproval, ExpiresAt: &expiredAt},
+		})
+
+		engine, _ := NewRulesEngine(fileManager, WithApprovedPhones(store))
+		decision := engine.Verify(context.Background(), &models.Applicant{
+			Age:                1,
+			PoliticallyExposed: &PPE,
+			PhoneNumber:        approvedPhoneNumber,
+		})
+
+		assert.Equal(t, StatusDeclined, decision.Status)
+		assert.False(t, decision.Bypassed)
+	})
+
+	t.Run("failed rule carries constraint and applicant value", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+			Rules: []models.RuleInfo{
+				{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: false}},
+				{Name: RuleIncome, Constraints: map[string]any{minSalaryConstraint: float64(150000)}},
+				{Name: RuleAge, Constraints: map[string]any{minAgeConstraint: float64(99)}},
+				{Name: RuleNoOfCreditCards, Constraints: map[string]any{}},
+				{Name: RulePoliticallyExposed, Constraints: map[string]any{isExposedConstraint: false}},
+				{Name: RulePhone, Constraints: map[string]any{}},
+			},
+		}, nil)
+
+		engine, _ := NewRulesEngine(fileManager)
+		decision := engine.Verify(context.Background(), &models.Applicant{
+			Income:              120000,
+			NumberOfCreditCards: 2,
+			Age:                 1,
+			PoliticallyExposed:  &PPE,
+			PhoneNumber:         "202-324-0507",
+		})
+
+		assert.Equal(t, StatusDeclined, decision.Status)
+		assert.False(t, decision.Bypassed)
+
+		failed := decision.Rules[len(decision.Rules)-1]
+		assert.False(t, failed.Passed())
+		assert.Equal(t, RuleIncome, failed.Name)
+		assert.Equal(t, IncomeConstraints{MinimumSalary: 150000}, failed.Constraints)
+		assert.Equal(t, 120000, failed.Actual)
+	})
+}
+
+func Test_PhoneLocationRule(t *testing.T) {
+	tests := []struct {
+		name            string
+		constraints     map[string]any
+		phoneNumber     string
+		expectedOutcome Outcome
+		expectedActual  any
+		expectedErr     string
+	}{
+		{
+			name:            "first digit of a formatted number",
+			phoneNumber:     "(486) 356 0375",
+			expectedOutcome: OutcomeFail,
+		},
+		{
+			name:            "country code is not the area code",
+			phoneNumber:     "+1 286 356 0375",
+			expectedOutcome: OutcomePass,
+		},
+		{
+			name:            "full area code",
+			constraints:     map[string]any{"area_code": "npa", "allowed_area_codes": []any{"486"}},
+			phoneNumber:     "+1 486.356.0375",
+			expectedOutcome: OutcomePass,
+		},
+		{
+			name:            "unparsable number",
+			phoneNumber:     "356-0375",
+			expectedOutcome: OutcomeFail,
+		},
+		{
+			name:            "malformed number in an allowed area",
+			phoneNumber:     "286-35a-0375",
+			expectedOutcome: OutcomeFail,
+			expectedActual:  map[string]any{"phone_number": "286-35a-0375", "error": `invalid phone number: unexpected 'a' in "286-35a-0375"`},
+		},
+		{
+			name:            "missing number",
+			expectedOutcome: OutcomeFail,
+		},
+		{
+			name:        "area codes must match the extraction",
+			constraints: map[string]any{"area_code": "npa", "allowed_area_codes": []any{"4"}},
+			expectedErr: `area code "4" must have 3 digit(s) with npa extraction`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := newPhoneLocationRule(tt.constraints)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			res := rule.Execute(context.Background(), models.Applicant{PhoneNumber: tt.phoneNumber})
+			assert.Equal(t, tt.expectedOutcome, res.Outcome)
+			if tt.expectedActual != nil {
+				assert.Equal(t, tt.expectedActual, res.Actual)
+			}
+		})
+	}
+
+	t.Run("approved phones match however they are written", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: mockRules}, nil)
+		PPE := false
+
+		engine, _ := NewRulesEngine(fileManager, WithApprovedPhones(helpers.NewMemoryApprovedPhoneStore(helpers.ApprovedPhone{Phone: "486-356-0375"})))
+		for _, number := range []string{"486-356-0375", "(486) 356 0375", "+1 486 356 0375"} {
+			assert.True(t, engine.Verify(context.Background(), &models.Applicant{PoliticallyExposed: &PPE, PhoneNumber: number}).Bypassed, number)
+		}
+	})
+}
+
+func Test_IndustryRule(t *testing.T) {
+	tests := []struct {
+		name            string
+		constraints     map[string]any
+		code            string
+		expectedOutcome Outcome
+	}{
+		{
+			name:            "blocked code",
+			constraints:     map[string]any{"codes": []any{"2-930"}},
+			code:            "2-930 - Exterior Plants",
+			expectedOutcome: OutcomeFail,
+		},
+		{
+			name:            "blocked division prefix",
+			constraints:     map[string]any{"prefixes": []any{"2"}},
+			code:            "2-930 - Exterior Plants",
+			expectedOutcome: OutcomeFail,
+		},
+		{
+			name:            "prefix does not match another division",
+			constraints:     map[string]any{"prefixes": []any{"2"}},
+			code:            "21-100 - Fire Suppression",
+			expectedOutcome: OutcomePass,
+		},
+		{
+			name:            "blocked range",
+			constraints:     map[string]any{"ranges": []any{"2-900..2-999"}},
+			code:            "2-930",
+			expectedOutcome: OutcomeFail,
+		},
+		{
+			name:            "allowed range",
+			constraints:     map[string]any{"action": IndustryAllow, "ranges": []any{"1..5"}},
+			code:            "13-900 - Fire Suppression",
+			expectedOutcome: OutcomeFail,
+		},
+		{
+			name:            "unparsable code",
+			constraints:     map[string]any{"codes": []any{"2-930"}},
+			code:            "Exterior Plants",
+			expectedOutcome: OutcomeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := newIndustryRule(tt.constraints, Dependencies{})
+			if !assert.NoError(t, err) {
+				return
+			}
+			res := rule.Execute(context.Background(), models.Applicant{JobIndustryCode: tt.code})
+			assert.Equal(t, tt.expectedOutcome, res.Outcome)
+		})
+	}
+
+	t.Run("codes are checked against the taxonomy", func(t *testing.T) {
+		taxonomy := filepath.Join(t.TempDir(), "industries.json")
+		ioutil.WriteFile(taxonomy, []byte(`{"2-930": "Exterior Plants"}`), 0644)
+		config := &models.RulesConfig{Rules: append([]models.RuleInfo{
+			{Name: "HighRiskIndustries", Kind: RuleIndustry, Constraints: map[string]any{"codes": []any{"2-931"}}},
+		}, mockRules...)}
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(config, nil)
+
+		_, err := NewRulesEngine(fileManager, WithIndustryTaxonomy(taxonomy))
+		assert.EqualError(t, err, `invalid rule HighRiskIndustries: unknown industry code "2-931"`)
+
+		ioutil.WriteFile(taxonomy, []byte(`{"2-930": "Exterior Plants", "2-931": "Interior Plants"}`), 0644)
+		engine, err := NewRulesEngine(fileManager, WithIndustryTaxonomy(taxonomy))
+		if !assert.NoError(t, err) {
+			return
+		}
+		PPE := false
+		decision := engine.Verify(context.Background(), &models.Applicant{PoliticallyExposed: &PPE, JobIndustryCode: "2-931", PhoneNumber: "202-324-0507"}, WithMode(ModeEvaluateAll))
+		assert.Contains(t, decision.FailedRules, "HighRiskIndustries")
+		assert.Equal(t, industry.Code{Code: "2-931", Division: "2", Section: "931", Description: "Interior Plants"}, decision.Rules[1].Actual)
+	})
+
+	t.Run("no codes at all", func(t *testing.T) {
+		_, err := newIndustryRule(map[string]any{"action": IndustryAllow}, Dependencies{})
+		assert.EqualError(t, err, "no codes, prefixes or ranges given")
+	})
+}
+
+func Test_NoOfCreditCardsRule(t *testing.T) {
+	PPE := false
+	applicant := models.Applicant{Income: 120000, NumberOfCreditCards: 1, Age: 30, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507"}
+	config := &models.RulesConfig{Rules: []models.RuleInfo{
+		{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: false}},
+		{Name: RuleNoOfCreditCards, Constraints: map[string]any{"max_credit_card_allowed": 3}},
+		{Name: RuleIncome, Constraints: map[string]any{}},
+		{Name: RuleAge, Constraints: map[string]any{}},
+		{Name: RulePoliticallyExposed, Constraints: map[string]any{isExposedConstraint: false}},
+		{Name: RulePhone, Constraints: map[string]any{}},
+	}}
+	scoredAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		score          risk.Score
+		err            error
+		expectedStatus Status
+		expectedRisk   any
+	}{
+		{
+			name:           "low risk is approved",
+			score:          risk.Score{Value: 780, Band: risk.BandLow, Provider: "bureau", ScoredAt: scoredAt},
+			expectedStatus: StatusApproved,
+			expectedRisk:   risk.BandLow,
+		},
+		{
+			name:           "high risk is declined",
+			score:          risk.Score{Value: 520, Band: risk.BandHigh, Provider: "bureau", ScoredAt: scoredAt},
+			expectedStatus: StatusDeclined,
+			expectedRisk:   risk.BandHigh,
+		},
+		{
+			name:           "provider errors are reported",
+			err:            fmt.Errorf("bureau is unavailable"),
+			expectedStatus: StatusError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileManager := mocks.NewFileManager(t)
+			fileManager.On("LoadRulesFromConfig").Return(config, nil)
+			provider := riskmocks.NewRiskProvider(t)
+			provider.On("Score", mock.Anything, applicant).Return(tt.score, tt.err)
+
+			engine, err := NewRulesEngine(fileManager, WithRiskProvider(provider))
+			assert.NoError(t, err)
+			decision := engine.Verify(context.Background(), &applicant)
+
+			assert.Equal(t, tt.expectedStatus, decision.Status)
+			res := decision.Rules[1]
+			assert.Equal(t, RuleNoOfCreditCards, res.Name)
+			if tt.err != nil {
+				assert.Equal(t, "failed to score credit risk: bureau is unavailable", res.Error)
+				return
+			}
+			actual := res.Actual.(map[string]any)
+			assert.Equal(t, tt.expectedRisk, actual["credit_risk"])
+			assert.Equal(t, tt.score, actual["risk_score"])
+		})
+	}
+
+	t.Run("too many cards are declined without a score", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(config, nil)
+		provider := riskmocks.NewRiskProvider(t) // no expectations, Score must not be called
+
+		engine, err := NewRulesEngine(fileManager, WithRiskProvider(provider))
+		assert.NoError(t, err)
+		applicant := applicant
+		applicant.NumberOfCreditCards = 4
+		decision := engine.Verify(context.Background(), &applicant)
+
+		assert.Equal(t, StatusDeclined, decision.Status)
+		res := decision.Rules[1]
+		assert.Equal(t, RuleNoOfCreditCards, res.Name)
+		assert.Equal(t, OutcomeFail, res.Outcome)
+		assert.Equal(t, map[string]any{"number_of_credit_cards": 4}, res.Actual)
+	})
+
+	t.Run("the calculated risk is the default", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(config, nil)
+
+		engine, _ := NewRulesEngine(fileManager)
+		decision := engine.Verify(context.Background(), &applicant)
+
+		score := decision.Rules[1].Actual.(map[string]any)["risk_score"].(risk.Score)
+		assert.Equal(t, risk.CalculatedProviderName, score.Provider)
+		assert.Equal(t, risk.CalculateCreditRisk(applicant.Age, applicant.NumberOfCreditCards), score.Band)
+	})
+}
+
+func Test_DenyListRule(t *testing.T) {
+	PPE := false
+	phone := "501-324-0507"
+	denyRules := append([]models.RuleInfo{{Name: RuleDenyList}}, mockRules...)
+
+	t.Run("denied applicants are declined before the bypass", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: denyRules}, nil)
+		denyList := helpers.NewMemoryDenyListStore(helpers.DeniedValue{Field: "phone_number", Value: phone, Listing: helpers.Listing{Reason: "confirmed fraud"}})
+
+		engine, _ := NewRulesEngine(fileManager,
+			WithApprovedPhones(helpers.NewMemoryApprovedPhoneStore(helpers.ApprovedPhone{Phone: phone})),
+			WithDenyList(denyList),
+		)
+		decision := engine.Verify(context.Background(), &models.Applicant{PoliticallyExposed: &PPE, PhoneNumber: phone}, WithMode(ModeEvaluateAll))
+
+		assert.Equal(t, StatusDeclined, decision.Status)
+		assert.True(t, decision.Denied)
+		assert.False(t, decision.Bypassed)
+		assert.Equal(t, "confirmed fraud", decision.DenyReason)
+		assert.Equal(t, []string{RuleDenyList}, decision.FailedRules)
+		assert.Len(t, decision.Rules, 1)
+		assert.Equal(t, RuleDenyList, engine.RuleNames()[0])
+	})
+
+	t.Run("industry codes are denied by their code", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: denyRules}, nil)
+		applicant := &models.Applicant{PoliticallyExposed: &PPE, PhoneNumber: phone, JobIndustryCode: "2-930 - Exterior Plants"}
+
+		engine, _ := NewRulesEngine(fileManager)
+		assert.False(t, engine.Verify(context.Background(), applicant).Denied)
+
+		assert.NoError(t, engine.DenyList().Add(context.Background(), helpers.DeniedValue{Field: "job_industry_code", Value: "2-930"}))
+		decision := engine.Verify(context.Background(), applicant)
+		assert.True(t, decision.Denied)
+		assert.Equal(t, "job_industry_code 2-930 is on the deny list", decision.DenyReason)
+	})
+
+	t.Run("lookup failures refer the applicant", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: denyRules}, nil)
+		denyList := mocks.NewDenyListStore(t)
+		denyList.On("Get", mock.Anything, "phone_number", phone).Return(nil, fmt.Errorf("connection refused"))
+
+		engine, _ := NewRulesEngine(fileManager, WithDenyList(denyList))
+		decision := engine.Verify(context.Background(), &models.Applicant{
+			Income:              120000,
+			NumberOfCreditCards: 0,
+			Age:                 30,
+			PoliticallyExposed:  &PPE,
+			PhoneNumber:         phone,
+		})
+
+		assert.Equal(t, StatusReferred, decision.Status)
+		assert.False(t, decision.Denied)
+		assert.Equal(t, []string{RuleDenyList}, decision.ErroredRules)
+		assert.Len(t, decision.Rules, len(denyRules))
+	})
+
+	t.Run("unknown fields are rejected", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: append([]models.RuleInfo{
+			{Name: RuleDenyList, Constraints: map[string]any{"fields": []any{"email"}}},
+		}, mockRules...)}, nil)
+
+		_, err := NewRulesEngine(fileManager)
+		assert.Error(t, err)
+	})
+
+	t.Run("the deny rule is found by its kind", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: append(append([]models.RuleInfo{}, mockRules...),
+			models.RuleInfo{Name: "Blocklist", Kind: RuleDenyList},
+			models.RuleInfo{Name: RuleDenyList, Kind: RuleCompare, Constraints: map[string]any{"field": "age", "operator": ">=", "value": 18}},
+		)}, nil)
+		denyList := helpers.NewMemoryDenyListStore(helpers.DeniedValue{Field: "phone_number", Value: phone})
+
+		engine, err := NewRulesEngine(fileManager, WithApprovedPhones(helpers.NewMemoryApprovedPhoneStore(helpers.ApprovedPhone{Phone: phone})), WithDenyList(denyList))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, []string{"Blocklist", RuleMaster}, engine.RuleNames()[:2])
+		assert.Equal(t, RuleDenyList, engine.RuleNames()[len(engine.RuleNames())-1])
+
+		decision := engine.Verify(context.Background(), &models.Applicant{PoliticallyExposed: &PPE, PhoneNumber: phone}, WithMode(ModeEvaluateAll))
+		assert.True(t, decision.Denied)
+		assert.Equal(t, []string{"Blocklist"}, decision.FailedRules)
+		assert.Len(t, decision.Rules, 1)
+	})
+}
+
+func Test_RulesEngine_Verify_Order(t *testing.T) {
+	PPE := false
+	applicant := &models.Applicant{
+		Income:              90000,
+		NumberOfCreditCards: 2,
+		Age:                 1,
+		PoliticallyExposed:  &PPE,
+		PhoneNumber:         "202-324-0507",
+	}
+	ruleInfos := func(agePriority int) []models.RuleInfo {
+		return []models.RuleInfo{
+			{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: false}},
+			{Name: RuleIncome, Constraints: map[string]any{}},
+			{Name: RuleNoOfCreditCards, Constraints: map[string]any{}},
+			{Name: RuleAge, Constraints: map[string]any{}, Priority: agePriority},
+			{Name: RulePoliticallyExposed, Constraints: map[string]any{isExposedConstraint: false}},
+			{Name: RulePhone, Constraints: map[string]any{}},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		agePriority   int
+		expectedRules []string
+	}{
+		{
+			name:          "config order is kept without priorities",
+			expectedRules: []string{RuleMaster, RuleIncome},
+		},
+		{
+			name:          "lower priority runs first",
+			agePriority:   -1,
+			expectedRules: []string{RuleMaster, RuleAge},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileManager := mocks.NewFileManager(t)
+			fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: ruleInfos(tt.agePriority)}, nil)
+			engine, _ := NewRulesEngine(fileManager)
+
+			for i := 0; i < 20; i++ {
+				decision := engine.Verify(context.Background(), applicant)
+
+				var names []string
+				for _, r := range decision.Rules {
+					names = append(names, r.Name)
+				}
+				assert.Equal(t, tt.expectedRules, names)
+			}
+		})
+	}
+}
+
+func Test_RulesEngine_Verify_Mode(t *testing.T) {
+	PPE := false
+	PPEYES := true
+	approvedPhoneNumber := "501-324-0507"
+	oneApprovedPhone := helpers.NewMemoryApprovedPhoneStore(helpers.ApprovedPhone{Phone: approvedPhoneNumber})
+
+	tests := []struct {
+		name           string
+		configMode     string
+		opts           []VerifyOption
+		applicant      *models.Applicant
+		expectedStatus Status
+		expectedFailed []string
+		bypassed       bool
+	}{
+		{
+			name: "short-circuit stops at the first failure by default",
+			applicant: &models.Applicant{
+				Income:              90000,
+				NumberOfCreditCards: 2,
+				Age:                 1,
+				PoliticallyExposed:  &PPEYES,
+				PhoneNumber:         "402-324-0507",
+			},
+			expectedStatus: StatusDeclined,
+			expectedFailed: []string{RuleIncome},
+		},
+		{
+			name:       "evaluate-all from config collects every failure",
+			configMode: ModeEvaluateAll,
+			applicant: &models.Applicant{
+				Income:              90000,
+				NumberOfCreditCards: 2,
+				Age:                 1,
+				PoliticallyExposed:  &PPEYES,
+				PhoneNumber:         "402-324-0507",
+			},
+			expectedStatus: StatusDeclined,
+			expectedFailed: []string{RuleIncome, RuleAge, RulePoliticallyExposed, RulePhone},
+		},
+		{
+			name:       "per request override wins over config",
+			configMode: ModeEvaluateAll,
+			opts:       []VerifyOption{WithMode(ModeShortCircuit)},
+			applicant: &models.Applicant{
+				Income:              90000,
+				NumberOfCreditCards: 2,
+				Age:                 1,
+				PoliticallyExposed:  &PPEYES,
+				PhoneNumber:         "402-324-0507",
+			},
+			expectedStatus: StatusDeclined,
+			expectedFailed: []string{RuleIncome},
+		},
+		{
+			name: "evaluate-all still reports failures for bypassed applicants",
+			opts: []VerifyOption{WithMode(ModeEvaluateAll)},
+			applicant: &models.Applicant{
+				Income:              120000,
+				NumberOfCreditCards: 2,
+				Age:                 1,
+				PoliticallyExposed:  &PPE,
+				PhoneNumber:         approvedPhoneNumber,
+			},
+			expectedStatus: StatusApproved,
+			expectedFailed: []string{RuleAge},
+			bypassed:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileManager := mocks.NewFileManager(t)
+			fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+				Mode: tt.configMode,
+				Rules: []models.RuleInfo{
+					{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: true}},
+					{Name: RuleIncome, Constraints: map[string]any{}},
+					{Name: RuleAge, Constraints: map[string]any{}},
+					{Name: RuleNoOfCreditCards, Constraints: map[string]any{}},
+					{Name: RulePoliticallyExposed, Constraints: map[string]any{isExposedConstraint: false}},
+					{Name: RulePhone, Constraints: map[string]any{}},
+				},
+			}, nil)
+
+			engine, _ := NewRulesEngine(fileManager, WithApprovedPhones(oneApprovedPhone))
+			decision := engine.Verify(context.Background(), tt.applicant, tt.opts...)
+
+			assert.Equal(t, tt.expectedStatus, decision.Status)
+			assert.Equal(t, tt.expectedFailed, decision.FailedRules)
+			assert.Equal(t, tt.bypassed, decision.Bypassed)
+		})
+	}
+}
+
+func Test_CompareRule(t *testing.T) {
+	PPE := false
+
+	tests := []struct {
+		name        string
+		constraints map[string]any
+		applicant   models.Applicant
+		expected    bool
+		wantErr     bool
+	}{
+		{
+			name:        "max age passes",
+			constraints: map[string]any{"field": "age", "operator": "<=", "value": float64(65)},
+			applicant:   models.Applicant{Age: 65},
+			expected:    true,
+		},
+		{
+			name:        "max age fails",
+			constraints: map[string]any{"field": "age", "operator": "<", "value": 65},
+			applicant:   models.Applicant{Age: 65},
+			expected:    false,
+		},
+		{
+			name:        "income between",
+			constraints: map[string]any{"field": "income", "operator": "between", "value": []any{float64(1000), float64(2000)}},
+			applicant:   models.Applicant{Income: 2000},
+			expected:    true,
+		},
+		{
+			name:        "industry code not in list",
+			constraints: map[string]any{"field": "job_industry_code", "operator": "not_in", "value": []string{"15-100 - Plumbing"}},
+			applicant:   models.Applicant{JobIndustryCode: "15-100 - Plumbing"},
+			expected:    false,
+		},
+		{
+			name:        "industry code matches",
+			constraints: map[string]any{"field": "job_industry_code", "operator": "matches", "value": "^15-"},
+			applicant:   models.Applicant{JobIndustryCode: "15-100 - Plumbing"},
+			expected:    true,
+		},
+		{
+			name:        "bool equality",
+			constraints: map[string]any{"field": "politically_exposed", "operator": "!=", "value": true},
+			applicant:   models.Applicant{PoliticallyExposed: &PPE},
+			expected:    true,
+		},
+
+		{
+			name:        "unknown field",
+			constraints: map[string]any{"field": "salary", "operator": ">", "value": 1},
+			wantErr:     true,
+		},
+		{
+			name:        "unknown operator",
+			constraints: map[string]any{"field": "age", "operator": "~", "value": 1},
+			wantErr:     true,
+		},
+		{
+			name:        "operator not valid for field",
+			constraints: map[string]any{"field": "phone_number", "operator": ">", "value": 1},
+			wantErr:     true,
+		},
+		{
+			name:        "value type mismatch",
+			constraints: map[string]any{"field": "age", "operator": "==", "value": "18"},
+			wantErr:     true,
+		},
+		{
+			name:        "invalid between range",
+			constraints: map[string]any{"field": "age", "operator": "between", "value": []any{float64(30), float64(18)}},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := newCompareRule(tt.constraints)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, rule.Execute(context.Background(), tt.applicant).Passed())
+			}
+		})
+	}
+}
+
+func Test_NewRulesEngine_CompareRule(t *testing.T) {
+	PPE := false
+	withMaxAge := func(constraints map[string]any) *models.RulesConfig {
+		return &models.RulesConfig{
+			Rules: []models.RuleInfo{
+				{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: true}},
+				{Name: RuleIncome, Constraints: map[string]any{}},
+				{Name: RuleAge, Constraints: map[string]any{}},
+				{Name: RuleNoOfCreditCards, Constraints: map[string]any{}},
+				{Name: RulePoliticallyExposed, Constraints: map[string]any{isExposedConstraint: false}},
+				{Name: RulePhone, Constraints: map[string]any{}},
+				{Name: "MaxAge", Kind: RuleCompare, Constraints: constraints},
+			},
+		}
+	}
+
+	t.Run("invalid compare rule fails engine creation", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(withMaxAge(map[string]any{"field": "age", "operator": "<="}), nil)
 
-			assert.Equal(t, tt.expected, status)
+		_, err := NewRulesEngine(fileManager)
+		assert.EqualError(t, err, "invalid rules config: rules[6](MaxAge).constraints.value: is required")
+	})
+
+	t.Run("compare rule takes part in verification", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(withMaxAge(map[string]any{"field": "age", "operator": "<=", "value": 65}), nil)
+
+		engine, err := NewRulesEngine(fileManager)
+		if !assert.NoError(t, err) {
+			return
+		}
+		decision := engine.Verify(context.Background(), &models.Applicant{
+			Income:              120000,
+			NumberOfCreditCards: 1,
+			Age:                 71,
+			PoliticallyExposed:  &PPE,
+			PhoneNumber:         "202-324-0507",
 		})
+
+		assert.Equal(t, StatusDeclined, decision.Status)
+		assert.Equal(t, []string{"MaxAge"}, decision.FailedRules)
+	})
+}
+
+func Test_GroupRule(t *testing.T) {
+	PPE := false
+	policy := models.RuleInfo{
+		Name: "IncomeOrSeniority",
+		Kind: GroupAnyOf,
+		Rules: []models.RuleInfo{
+			{Name: RuleIncome, Constraints: map[string]any{minSalaryConstraint: 100000}},
+			{
+				Name: "Seniority",
+				Kind: GroupAllOf,
+				Rules: []models.RuleInfo{
+					{Name: "MinAge25", Kind: RuleCompare, Constraints: map[string]any{"field": "age", "operator": ">=", "value": 25}},
+					{Name: "SingleCard", Kind: RuleCompare, Constraints: map[string]any{"field": "number_of_credit_cards", "operator": "<=", "value": 1}},
+				},
+			},
+		},
+	}
+	config := func(mode Mode) *models.RulesConfig {
+		return &models.RulesConfig{
+			Mode: mode,
+			Rules: []models.RuleInfo{
+				{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: false}},
+				{Name: RuleAge, Constraints: map[string]any{}},
+				{Name: RuleNoOfCreditCards, Constraints: map[string]any{}},
+				{Name: RulePoliticallyExposed, Constraints: map[string]any{isExposedConstraint: false}},
+				{Name: RulePhone, Constraints: map[string]any{}},
+				policy,
+			},
+		}
 	}
+
+	tests := []struct {
+		name             string
+		mode             Mode
+		applicant        *models.Applicant
+		expected         Status
+		expectedChildren []bool
+	}{
+		{
+			name: "high income passes without seniority",
+			applicant: &models.Applicant{
+				Income: 120000, NumberOfCreditCards: 2, Age: 19, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507",
+			},
+			expected:         StatusApproved,
+			expectedChildren: []bool{true},
+		},
+		{
+			name: "seniority passes without high income",
+			applicant: &models.Applicant{
+				Income: 50000, NumberOfCreditCards: 1, Age: 26, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507",
+			},
+			expected:         StatusApproved,
+			expectedChildren: []bool{false, true},
+		},
+		{
+			name: "neither branch passes",
+			applicant: &models.Applicant{
+				Income: 50000, NumberOfCreditCards: 2, Age: 19, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507",
+			},
+			expected:         StatusDeclined,
+			expectedChildren: []bool{false, false},
+		},
+		{
+			name: "evaluate-all runs every branch",
+			mode: ModeEvaluateAll,
+			applicant: &models.Applicant{
+				Income: 120000, NumberOfCreditCards: 2, Age: 19, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507",
+			},
+			expected:         StatusApproved,
+			expectedChildren: []bool{true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileManager := mocks.NewFileManager(t)
+			fileManager.On("LoadRulesFromConfig").Return(config(tt.mode), nil)
+
+			engine, err := NewRulesEngine(fileManager)
+			if !assert.NoError(t, err) {
+				return
+			}
+			decision := engine.Verify(context.Background(), tt.applicant)
+			assert.Equal(t, tt.expected, decision.Status)
+
+			group := decision.Rules[len(decision.Rules)-1]
+			assert.Equal(t, "IncomeOrSeniority", group.Name)
+			assert.Equal(t, GroupAnyOf, group.Group)
+
+			var children []bool
+			for _, child := range group.Children {
+				children = append(children, child.Passed())
+			}
+			assert.Equal(t, tt.expectedChildren, children)
+		})
+	}
+
+	t.Run("none_of passes only when no rule passes", func(t *testing.T) {
+		rule, err := newGroupRule(models.RuleInfo{
+			Kind: GroupNoneOf,
+			Rules: []models.RuleInfo{
+				{Name: "Plumber", Kind: RuleCompare, Constraints: map[string]any{"field": "job_industry_code", "operator": "matches", "value": "^15-100"}},
+			},
+		}, Dependencies{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.False(t, rule.Execute(context.Background(), models.Applicant{JobIndustryCode: "15-100 - Plumbing"}).Passed())
+		assert.True(t, rule.Execute(context.Background(), models.Applicant{JobIndustryCode: "2-930 - Exterior Plants"}).Passed())
+	})
+
+	t.Run("empty and invalid groups are rejected", func(t *testing.T) {
+		_, err := newGroupRule(models.RuleInfo{Kind: GroupAllOf}, Dependencies{})
+		assert.Error(t, err)
+
+		_, err = newGroupRule(models.RuleInfo{Kind: GroupAllOf, Rules: []models.RuleInfo{{Name: "Unknown"}}}, Dependencies{})
+		assert.Error(t, err)
+	})
+}
+
+func Test_ExpressionRule(t *testing.T) {
+	t.Run("compiles once and evaluates against the applicant", func(t *testing.T) {
+		rule, err := newExpressionRule(map[string]any{"expression": "income / (number_of_credit_cards + 1) > 30000"})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		res := rule.Execute(context.Background(), models.Applicant{Income: 90000, NumberOfCreditCards: 2})
+		assert.False(t, res.Passed())
+		assert.Equal(t, map[string]any{"income": float64(90000), "number_of_credit_cards": float64(2)}, res.Actual)
+		assert.True(t, rule.Execute(context.Background(), models.Applicant{Income: 90001, NumberOfCreditCards: 2}).Passed())
+	})
+
+	t.Run("rejects unknown fields when the engine is built", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+			Rules: append(append([]models.RuleInfo(nil), mockRules...), models.RuleInfo{
+				Name:        "IncomePerCard",
+				Kind:        RuleExpression,
+				Constraints: map[string]any{"expression": "salary > 1"},
+			}),
+		}, nil)
+
+		_, err := NewRulesEngine(fileManager)
+		assert.EqualError(t, err, `invalid rule IncomePerCard: invalid expression "salary > 1": unknown field "salary" at 0`)
+	})
+}
+
+func Test_RulesEngine_Reload(t *testing.T) {
+	PPE := false
+	applicant := &models.Applicant{
+		Income:              120000,
+		NumberOfCreditCards: 1,
+		Age:                 23,
+		PoliticallyExposed:  &PPE,
+		PhoneNumber:         "202-324-0507",
+	}
+	withMinSalary := func(minSalary int) *models.RulesConfig {
+		return &models.RulesConfig{
+			Rules: []models.RuleInfo{
+				{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: false}},
+				{Name: RuleIncome, Constraints: map[string]any{minSalaryConstraint: float64(minSalary)}},
+				{Name: RuleAge, Constraints: map[string]any{}},
+				{Name: RuleNoOfCreditCards, Constraints: map[string]any{}},
+				{Name: RulePoliticallyExposed, Constraints: map[string]any{isExposedConstraint: false}},
+				{Name: RulePhone, Constraints: map[string]any{}},
+			},
+		}
+	}
+
+	fileManager := mocks.NewFileManager(t)
+	fileManager.On("LoadRulesFromConfig").Return(withMinSalary(100000), nil).Once()
+	engine, err := NewRulesEngine(fileManager)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, StatusApproved, engine.Verify(context.Background(), applicant).Status)
+
+	fileManager.On("LoadRulesFromConfig").Return(withMinSalary(150000), nil).Once()
+	assert.NoError(t, engine.Reload())
+	assert.Equal(t, StatusDeclined, engine.Verify(context.Background(), applicant).Status)
+
+	fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: []models.RuleInfo{{Name: RuleIncome}}}, nil).Once()
+	assert.EqualError(t, engine.Reload(), "missing required rules Master, Age, NoOfCreditCards, PhoneLocation, PoliticallyExposed, please check rules.json")
+	assert.Equal(t, StatusDeclined, engine.Verify(context.Background(), applicant).Status, "previous rules must be kept")
+
+	fileManager.On("LoadRulesFromConfig").Return(nil, fmt.Errorf("unexpected end of JSON input")).Once()
+	assert.EqualError(t, engine.Reload(), "failed to load rules: unexpected end of JSON input")
+	assert.Equal(t, StatusDeclined, engine.Verify(context.Background(), applicant).Status, "previous rules must be kept")
+}
+
+func Test_RulesEngine_Versions(t *testing.T) {
+	PPE := false
+	applicant := &models.Applicant{
+		Income:              120000,
+		NumberOfCreditCards: 1,
+		Age:                 23,
+		PoliticallyExposed:  &PPE,
+		PhoneNumber:         "202-324-0507",
+	}
+	withMinSalary := func(label string, minSalary int) *models.RulesConfig {
+		return &models.RulesConfig{
+			Label: label,
+			Rules: []models.RuleInfo{
+				{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: false}},
+				{Name: RuleIncome, Constraints: map[string]any{minSalaryConstraint: float64(minSalary)}},
+				{Name: RuleAge, Constraints: map[string]any{}},
+				{Name: RuleNoOfCreditCards, Constraints: map[string]any{}},
+				{Name: RulePoliticallyExposed, Constraints: map[string]any{isExposedConstraint: false}},
+				{Name: RulePhone, Constraints: map[string]any{}},
+			},
+		}
+	}
+	historyDir := t.TempDir()
+
+	fileManager := mocks.NewFileManager(t)
+	fileManager.On("LoadRulesFromConfig").Return(withMinSalary("v1", 100000), nil).Once()
+	engine, err := NewRulesEngine(fileManager, WithHistoryDir(historyDir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	v1 := engine.Version()
+	assert.Len(t, v1.ID, 12)
+	assert.Equal(t, "v1", v1.Label)
+
+	decision := engine.Verify(context.Background(), applicant)
+	assert.Equal(t, v1.ID, decision.RulesVersion)
+	assert.Equal(t, "v1", decision.RulesLabel)
+
+	fileManager.On("LoadRulesFromConfig").Return(withMinSalary("v2", 150000), nil).Once()
+	assert.NoError(t, engine.Reload())
+	v2 := engine.Version()
+	assert.NotEqual(t, v1.ID, v2.ID)
+
+	decision = engine.Verify(context.Background(), applicant)
+	assert.Equal(t, StatusDeclined, decision.Status)
+	assert.Equal(t, v2.ID, decision.RulesVersion)
+
+	versions := engine.Versions()
+	if assert.Len(t, versions, 2) {
+		assert.Equal(t, v2.ID, versions[0].ID)
+		assert.True(t, versions[0].Active)
+		assert.Equal(t, v1.ID, versions[1].ID)
+		assert.False(t, versions[1].Active)
+	}
+
+	t.Run("rollback by label", func(t *testing.T) {
+		rolledBack, err := engine.Rollback("v1")
+		assert.NoError(t, err)
+		assert.Equal(t, v1.ID, rolledBack.ID)
+
+		decision := engine.Verify(context.Background(), applicant)
+		assert.Equal(t, StatusApproved, decision.Status)
+		assert.Equal(t, v1.ID, decision.RulesVersion)
+	})
+
+	t.Run("rollback to an unknown version", func(t *testing.T) {
+		_, err := engine.Rollback("v3")
+		assert.ErrorIs(t, err, ErrUnknownVersion)
+		assert.Equal(t, v1.ID, engine.Version().ID)
+	})
+
+	t.Run("history survives a restart", func(t *testing.T) {
+		restarted := mocks.NewFileManager(t)
+		restarted.On("LoadRulesFromConfig").Return(withMinSalary("v1", 100000), nil).Once()
+		engine, err := NewRulesEngine(restarted, WithHistoryDir(historyDir))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, engine.Versions(), 2)
+
+		rolledBack, err := engine.Rollback(v2.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "v2", rolledBack.Label)
+		assert.Equal(t, StatusDeclined, engine.Verify(context.Background(), applicant).Status)
+	})
+}
+
+func Test_RulesEngine_Verify_Errors(t *testing.T) {
+	PPE := false
+	applicant := func(ppe *bool) *models.Applicant {
+		return &models.Applicant{
+			Income:              120000,
+			NumberOfCreditCards: 1,
+			Age:                 23,
+			PoliticallyExposed:  ppe,
+			PhoneNumber:         "202-324-0507",
+		}
+	}
+	ruleInfos := func(extra ...models.RuleInfo) *models.RulesConfig {
+		return &models.RulesConfig{
+			Rules: append([]models.RuleInfo{
+				{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: true}},
+				{Name: RuleIncome, Constraints: map[string]any{}},
+				{Name: RuleAge, Constraints: map[string]any{}},
+				{Name: RuleNoOfCreditCards, Constraints: map[string]any{}},
+				{Name: RulePoliticallyExposed, Constraints: map[string]any{isExposedConstraint: false}},
+				{Name: RulePhone, Constraints: map[string]any{}},
+			}, extra...),
+		}
+	}
+	missingCardsRatio := func(onError string) models.RuleInfo {
+		return models.RuleInfo{
+			Name:        "IncomePerCard",
+			Kind:        RuleExpression,
+			OnError:     onError,
+			Constraints: map[string]any{"expression": "income / (number_of_credit_cards - 1) > 30000"},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		config          *models.RulesConfig
+		applicant       *models.Applicant
+		phonesErr       error
+		expectedStatus  Status
+		expectedFailed  []string
+		expectedErrored []string
+	}{
+		{
+			name:           "approved phones lookup error does not bypass but keeps evaluating",
+			config:         ruleInfos(),
+			applicant:      applicant(&PPE),
+			phonesErr:      fmt.Errorf("failed to load approved list phones"),
+			expectedStatus: StatusApproved,
+		},
+		{
+			name: "approved phones lookup error is reported when the master rule reports errors",
+			config: &models.RulesConfig{
+				Rules: append([]models.RuleInfo{{Name: RuleMaster, OnError: OnErrorReport, Constraints: map[string]any{checkApprovedPhoneConstraint: true}}}, ruleInfos().Rules[1:]...),
+			},
+			applicant:       applicant(&PPE),
+			phonesErr:       fmt.Errorf("failed to load approved list phones"),
+			expectedStatus:  StatusError,
+			expectedErrored: []string{RuleMaster},
+		},
+		{
+			name: "approved phones lookup error refers when the master rule refers errors",
+			config: &models.RulesConfig{
+				Rules: append([]models.RuleInfo{{Name: RuleMaster, OnError: OnErrorRefer, Constraints: map[string]any{checkApprovedPhoneConstraint: true}}}, ruleInfos().Rules[1:]...),
+			},
+			applicant:       applicant(&PPE),
+			phonesErr:       fmt.Errorf("failed to load approved list phones"),
+			expectedStatus:  StatusReferred,
+			expectedErrored: []string{RuleMaster},
+		},
+		{
+			name:            "rule error is reported instead of declining",
+			config:          ruleInfos(),
+			applicant:       applicant(nil),
+			expectedStatus:  StatusError,
+			expectedErrored: []string{RulePoliticallyExposed},
+		},
+		{
+			name:           "fail open treats the error as a pass",
+			config:         ruleInfos(missingCardsRatio(OnErrorFailOpen)),
+			applicant:      applicant(&PPE),
+			expectedStatus: StatusApproved,
+		},
+		{
+			name:           "fail closed treats the error as a failure",
+			config:         ruleInfos(missingCardsRatio(OnErrorFailClosed)),
+			applicant:      applicant(&PPE),
+			expectedStatus: StatusDeclined,
+			expectedFailed: []string{"IncomePerCard"},
+		},
+		{
+			name: "a failure after an error still declines",
+			config: &models.RulesConfig{
+				Rules: append([]models.RuleInfo{missingCardsRatio("")}, ruleInfos(models.RuleInfo{
+					Name: "MaxAge", Kind: RuleCompare, Constraints: map[string]any{"field": "age", "operator": "<", "value": 20},
+				}).Rules...),
+			},
+			applicant:       applicant(&PPE),
+			expectedStatus:  StatusDeclined,
+			expectedFailed:  []string{"MaxAge"},
+			expectedErrored: []string{"IncomePerCard"},
+		},
+		{
+			name: "an errored rule inside a group leaves the group undecided",
+			config: ruleInfos(models.RuleInfo{
+				Name:  "EitherRatio",
+				Kind:  GroupAnyOf,
+				Rules: []models.RuleInfo{missingCardsRatio(""), {Name: "Young", Kind: RuleCompare, Constraints: map[string]any{"field": "age", "operator": "<", "value": 20}}},
+			}),
+			applicant:       applicant(&PPE),
+			expectedStatus:  StatusError,
+			expectedErrored: []string{"EitherRatio"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileManager := mocks.NewFileManager(t)
+			fileManager.On("LoadRulesFromConfig").Return(tt.config, nil)
+			var approvedPhones helpers.ApprovedPhoneStore = helpers.NewMemoryApprovedPhoneStore()
+			if tt.phonesErr != nil {
+				store := mocks.NewApprovedPhoneStore(t)
+				store.On("Get", mock.Anything, mock.Anything).Return(nil, tt.phonesErr)
+				approvedPhones = store
+			}
+
+			engine, err := NewRulesEngine(fileManager, WithApprovedPhones(approvedPhones))
+			if !assert.NoError(t, err) {
+				return
+			}
+			decision := engine.Verify(context.Background(), tt.applicant)
+
+			assert.Equal(t, tt.expectedStatus, decision.Status)
+			assert.Equal(t, tt.expectedFailed, decision.FailedRules)
+			assert.Equal(t, tt.expectedErrored, decision.ErroredRules)
+			if tt.phonesErr != nil {
+				masterOutcome := OutcomeFail
+				if len(tt.expectedErrored) > 0 && tt.expectedErrored[0] == RuleMaster {
+					masterOutcome = OutcomeError
+				}
+				assert.Equal(t, masterOutcome, decision.Rules[0].Outcome)
+				assert.Contains(t, decision.Rules[0].Error, tt.phonesErr.Error())
+			}
+		})
+	}
+
+	t.Run("invalid on_error policy", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(ruleInfos(missingCardsRatio("ignore")), nil)
+
+		_, err := NewRulesEngine(fileManager)
+		assert.EqualError(t, err, `invalid rule IncomePerCard: invalid on_error policy "ignore"`)
+	})
+}
+
+func Test_RulesEngine_Verify_Referral(t *testing.T) {
+	PPE := false
+	PPEYES := true
+	config := func(extra ...models.RuleInfo) *models.RulesConfig {
+		return &models.RulesConfig{
+			Rules: append([]models.RuleInfo{
+				{Name: RuleMaster, Constraints: map[string]any{checkApprovedPhoneConstraint: false}},
+				{Name: RuleIncome, OnFail: OnFailRefer, Constraints: map[string]any{}},
+				{Name: "IncomeFloor", Kind: RuleCompare, Constraints: map[string]any{"field": "income", "operator": ">=", "value": 90000}},
+				{Name: RuleAge, Constraints: map[string]any{}},
+				{Name: RuleNoOfCreditCards, Constraints: map[string]any{}},
+				{Name: RulePoliticallyExposed, OnFail: OnFailRefer, Constraints: map[string]any{isExposedConstraint: false}},
+				{Name: RulePhone, Constraints: map[string]any{}},
+			}, extra...),
+		}
+	}
+
+	tests := []struct {
+		name             string
+		config           *models.RulesConfig
+		applicant        *models.Applicant
+		expectedStatus   Status
+		expectedReferred []string
+		expectedFailed   []string
+	}{
+		{
+			name:   "politically exposed applicant is referred",
+			config: config(),
+			applicant: &models.Applicant{
+				Income: 120000, NumberOfCreditCards: 1, Age: 23, PoliticallyExposed: &PPEYES, PhoneNumber: "202-324-0507",
+			},
+			expectedStatus:   StatusReferred,
+			expectedReferred: []string{RulePoliticallyExposed},
+		},
+		{
+			name:   "income just under the threshold is referred",
+			config: config(),
+			applicant: &models.Applicant{
+				Income: 95000, NumberOfCreditCards: 1, Age: 23, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507",
+			},
+			expectedStatus:   StatusReferred,
+			expectedReferred: []string{RuleIncome},
+		},
+		{
+			name:   "a decline wins over a referral",
+			config: config(),
+			applicant: &models.Applicant{
+				Income: 80000, NumberOfCreditCards: 1, Age: 23, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507",
+			},
+			expectedStatus:   StatusDeclined,
+			expectedReferred: []string{RuleIncome},
+			expectedFailed:   []string{"IncomeFloor"},
+		},
+		{
+			name: "an error wins over a referral",
+			config: config(models.RuleInfo{
+				Name: "IncomePerCard", Kind: RuleExpression, Constraints: map[string]any{"expression": "income / (number_of_credit_cards - 1) > 0"},
+			}),
+			applicant: &models.Applicant{
+				Income: 120000, NumberOfCreditCards: 1, Age: 23, PoliticallyExposed: &PPEYES, PhoneNumber: "202-324-0507",
+			},
+			expectedStatus:   StatusError,
+			expectedReferred: []string{RulePoliticallyExposed},
+		},
+		{
+			name: "an error can be sent to manual review",
+			config: config(models.RuleInfo{
+				Name: "IncomePerCard", Kind: RuleExpression, OnError: OnErrorRefer, Constraints: map[string]any{"expression": "income / (number_of_credit_cards - 1) > 0"},
+			}),
+			applicant: &models.Applicant{
+				Income: 120000, NumberOfCreditCards: 1, Age: 23, PoliticallyExposed: &PPE, PhoneNumber: "202-324-0507",
+			},
+			expectedStatus: StatusReferred,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileManager := mocks.NewFileManager(t)
+			fileManager.On("LoadRulesFromConfig").Return(tt.config, nil)
+
+			engine, err := NewRulesEngine(fileManager)
+			if !assert.NoError(t, err) {
+				return
+			}
+			decision := engine.Verify(context.Background(), tt.applicant)
+
+			assert.Equal(t, tt.expectedStatus, decision.Status)
+			assert.Equal(t, tt.expectedReferred, decision.ReferredRules)
+			assert.Equal(t, tt.expectedFailed, decision.FailedRules)
+		})
+	}
+
+	t.Run("invalid on_fail policy", func(t *testing.T) {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(config(models.RuleInfo{
+			Name: "MaxAge", Kind: RuleCompare, OnFail: "approve", Constraints: map[string]any{"field": "age", "operator": "<", "value": 99},
+		}), nil)
+
+		_, err := NewRulesEngine(fileManager)
+		assert.EqualError(t, err, `invalid rule MaxAge: invalid on_fail policy "approve"`)
+	})
+}
+
+func Test_NewRulesEngine_Schema(t *testing.T) {
+	config := func(overrides map[string]models.RuleInfo, extra ...models.RuleInfo) *models.RulesConfig {
+		ruleInfos := []models.RuleInfo{
+			{Name: RuleMaster, Constraints: map[string]any{"check_approved_phones": true}},
+			{Name: RuleIncome, Constraints: map[string]any{"minimum_salary": float64(100000)}},
+			{Name: RuleNoOfCreditCards, Constraints: map[string]any{"max_credit_card_allowed": float64(3)}},
+			{Name: RuleAge, Constraints: map[string]any{"min_age_allowed": float64(18)}},
+			{Name: RulePoliticallyExposed, Constraints: map[string]any{"is_pp_exposed": false}},
+			{Name: RulePhone, Constraints: map[string]any{"allowed_area_codes": []any{"0", "2", "5", "8"}}},
+		}
+		for i, ruleInfo := range ruleInfos {
+			if override, ok := overrides[ruleInfo.Name]; ok {
+				ruleInfos[i] = override
+			}
+		}
+		return &models.RulesConfig{Rules: append(ruleInfos, extra...)}
+	}
+
+	tests := []struct {
+		name        string
+		config      *models.RulesConfig
+		expectedErr string
+	}{
+		{
+			name:   "valid config",
+			config: config(nil),
+		},
+		{
+			name: "unknown constraint key",
+			config: config(map[string]models.RuleInfo{
+				RuleNoOfCreditCards: {Name: RuleNoOfCreditCards, Constraints: map[string]any{"max_credit_card_count": float64(3)}},
+			}),
+			expectedErr: "invalid rules config: rules[2](NoOfCreditCards).constraints.max_credit_card_count: unknown constraint",
+		},
+		{
+			name: "wrong type",
+			config: config(map[string]models.RuleInfo{
+				RulePoliticallyExposed: {Name: RulePoliticallyExposed, Constraints: map[string]any{"is_pp_exposed": "no"}},
+			}),
+			expectedErr: "invalid rules config: rules[4](PoliticallyExposed).constraints.is_pp_exposed: must be a bool, got no",
+		},
+		{
+			name: "out of range and not an integer",
+			config: config(map[string]models.RuleInfo{
+				RuleAge:             {Name: RuleAge, Constraints: map[string]any{"min_age_allowed": float64(200)}},
+				RuleNoOfCreditCards: {Name: RuleNoOfCreditCards, Constraints: map[string]any{"max_credit_card_allowed": 2.5}},
+			}),
+			expectedErr: "invalid rules config: " +
+				"rules[2](NoOfCreditCards).constraints.max_credit_card_allowed: must be an integer, got 2.5; " +
+				"rules[3](Age).constraints.min_age_allowed: must be at most 150, got 200",
+		},
+		{
+			name: "invalid list item",
+			config: config(map[string]models.RuleInfo{
+				RulePhone: {Name: RulePhone, Constraints: map[string]any{"allowed_area_codes": []any{"0", "2]"}}},
+			}),
+			expectedErr: "invalid rules config: rules[5](PhoneLocation).constraints.allowed_area_codes: item 1 must match ^[0-9]{1,3}$, got \"2]\"",
+		},
+		{
+			name:        "unknown rule kind",
+			config:      config(nil, models.RuleInfo{Name: "Fraud", Kind: "FraudCheck"}),
+			expectedErr: `invalid rules config: rules[6](Fraud).kind: unknown rule kind "FraudCheck"`,
+		},
+		{
+			name: "nested paths",
+			config: config(nil, models.RuleInfo{
+				Name: "Either",
+				Kind: GroupAnyOf,
+				Rules: []models.RuleInfo{
+					{Name: "Young", Kind: RuleCompare, Constraints: map[string]any{"field": "years", "operator": "<", "value": 20}},
+					{Kind: RuleExpression, Constraints: map[string]any{}},
+				},
+			}),
+			expectedErr: "invalid rules config: " +
+				"rules[6](Either).rules[0](Young).constraints.field: must be one of age, income, job_industry_code, number_of_credit_cards, phone_number, politically_exposed, got \"years\"; " +
+				"rules[6](Either).rules[1].rule_name: is required; " +
+				"rules[6](Either).rules[1].constraints.expression: is required",
+		},
+		{
+			name: "special rules",
+			config: config(nil,
+				models.RuleInfo{Name: "Bypass", Kind: RuleMaster},
+				models.RuleInfo{Name: "Either", Kind: GroupAnyOf, Rules: []models.RuleInfo{{Name: "Blocked", Kind: RuleDenyList}}},
+			),
+			expectedErr: "invalid rules config: " +
+				"rules[6](Bypass).kind: only one Master rule is allowed; " +
+				"rules[7](Either).rules[0](Blocked).kind: a DenyList rule can't be in a group",
+		},
+		{
+			name: "on_fail inside a group",
+			config: config(nil, models.RuleInfo{
+				Name:   "Either",
+				Kind:   GroupAnyOf,
+				OnFail: OnFailRefer,
+				Rules: []models.RuleInfo{
+					{Name: "Young", Kind: RuleCompare, OnFail: OnFailRefer, Constraints: map[string]any{"field": "age", "operator": "<", "value": 20}},
+					{Name: "Rich", Kind: RuleCompare, Constraints: map[string]any{"field": "income", "operator": ">", "value": 200000}},
+				},
+			}),
+			expectedErr: "invalid rules config: rules[6](Either).rules[0](Young).on_fail: is only allowed on top level rules, set it on the group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileManager := mocks.NewFileManager(t)
+			fileManager.On("LoadRulesFromConfig").Return(tt.config, nil)
+
+			_, err := NewRulesEngine(fileManager)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func Test_DecodeConstraints(t *testing.T) {
+	t.Run("defaults when missing", func(t *testing.T) {
+		config, err := decodeConstraints[PhoneLocationConstraints](nil)
+		assert.NoError(t, err)
+		assert.Equal(t, PhoneLocationConstraints{AllowedAreaCodes: []string{"0", "2", "5", "8"}, AreaCode: "first_digit"}, config)
+	})
+
+	t.Run("json numbers decode into ints", func(t *testing.T) {
+		config, err := decodeConstraints[IncomeConstraints](map[string]any{minSalaryConstraint: float64(150000)})
+		assert.NoError(t, err)
+		assert.Equal(t, IncomeConstraints{MinimumSalary: 150000}, config)
+	})
+
+	t.Run("unknown keys are rejected", func(t *testing.T) {
+		_, err := decodeConstraints[AgeConstraints](map[string]any{"max_age": 99})
+		assert.Error(t, err)
+	})
+
+	t.Run("compare fields match applicant fields", func(t *testing.T) {
+		fields := make([]string, 0, len(applicantFields))
+		for name := range applicantFields {
+			fields = append(fields, name)
+		}
+		compare, _ := lookupKind(RuleCompare)
+		assert.ElementsMatch(t, fields, compare.schema["field"].oneOf)
+	})
+}
+
+func Test_ConstraintsJSONSchema(t *testing.T) {
+	schema, ok := ConstraintsJSONSchema(RuleAge)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{
+		"min_age_allowed": map[string]any{"type": "integer", "minimum": float64(0), "maximum": float64(150), "default": float64(18)},
+	}, schema["properties"])
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	schema, _ = ConstraintsJSONSchema(RuleCompare)
+	assert.Equal(t, []string{"field", "operator", "value"}, schema["required"])
+
+	_, ok = ConstraintsJSONSchema("FraudCheck")
+	assert.False(t, ok)
+	assert.Len(t, ConstraintsJSONSchemas(), len(registeredKinds()))
+}
+
+type blocklistConstraints struct {
+	Phones []string `json:"phones" required:"true"`
+}
+
+type blocklistRule struct {
+	config blocklistConstraints
+}
+
+func (br *blocklistRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	for _, phone := range br.config.Phones {
+		if phone == applicant.PhoneNumber {
+			return result(false, br.config, applicant.PhoneNumber)
+		}
+	}
+	return result(true, br.config, applicant.PhoneNumber)
+}
+
+func Test_Register(t *testing.T) {
+	err := RegisterTyped("Blocklist", func(config blocklistConstraints, deps Dependencies) (ApprovalRule, error) {
+		return &blocklistRule{config: config}, nil
+	})
+	assert.NoError(t, err)
+	assert.EqualError(t, Register("Blocklist", func(map[string]any, Dependencies) (ApprovalRule, error) { return nil, nil }),
+		`rule kind "Blocklist" is already registered`)
+	assert.EqualError(t, Register(RuleIncome, func(map[string]any, Dependencies) (ApprovalRule, error) { return nil, nil }),
+		`rule kind "Income" is already registered`)
+	assert.EqualError(t, Register("Unchecked", nil), `rule kind "Unchecked" needs a factory`)
+
+	blocklist := models.RuleInfo{Name: "Blocked", Kind: "Blocklist", Constraints: map[string]any{"phones": []any{"0123456789"}}}
+
+	tests := []struct {
+		name           string
+		config         *models.RulesConfig
+		expectedErr    string
+		expectedStatus Status
+	}{
+		{
+			name:           "custom kind runs alongside built-in rules",
+			config:         &models.RulesConfig{Rules: append(append([]models.RuleInfo(nil), mockRules...), blocklist)},
+			expectedStatus: StatusDeclined,
+		},
+		{
+			name:           "only the configured rules are required",
+			config:         &models.RulesConfig{RequiredRules: []string{"Blocked"}, Rules: []models.RuleInfo{blocklist}},
+			expectedStatus: StatusDeclined,
+		},
+		{
+			name:        "required rule missing",
+			config:      &models.RulesConfig{RequiredRules: []string{"Blocked", RuleAge}, Rules: []models.RuleInfo{blocklist}},
+			expectedErr: "missing required rules Age, please check rules.json",
+		},
+		{
+			name: "custom constraints are validated",
+			config: &models.RulesConfig{RequiredRules: []string{}, Rules: []models.RuleInfo{
+				{Name: "Blocked", Kind: "Blocklist", Constraints: map[string]any{"phone": "0123456789"}},
+			}},
+			expectedErr: "invalid rules config: rules[0](Blocked).constraints.phone: unknown constraint; " +
+				"rules[0](Blocked).constraints.phones: is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileManager := mocks.NewFileManager(t)
+			fileManager.On("LoadRulesFromConfig").Return(tt.config, nil)
+
+			engine, err := NewRulesEngine(fileManager)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			decision := engine.Verify(context.Background(), &models.Applicant{PhoneNumber: "0123456789"}, WithMode(ModeEvaluateAll))
+			assert.Equal(t, tt.expectedStatus, decision.Status)
+			assert.Contains(t, decision.FailedRules, "Blocked")
+		})
+	}
+}
+
+func Test_Products(t *testing.T) {
+	fileManager := mocks.NewFileManager(t)
+	fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{Rules: mockRules}, nil)
+	engine, err := NewRulesEngine(fileManager)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = NewProducts("premium", map[string]*RulesEngine{DefaultProduct: engine})
+	assert.ErrorIs(t, err, ErrUnknownProduct)
+
+	products, err := NewProducts(DefaultProduct, map[string]*RulesEngine{DefaultProduct: engine, "secured": engine})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"secured", DefaultProduct}, products.Names())
+
+	got, name, err := products.Engine("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultProduct, name)
+	assert.Same(t, engine, got)
+
+	_, _, err = products.Engine("gold")
+	assert.ErrorIs(t, err, ErrUnknownProduct)
+	assert.EqualError(t, err, `unknown product "gold", expected one of secured, standard`)
+
+	assert.NoError(t, products.Reload())
 }
@@ -0,0 +1,417 @@
+// Package expr implements a small, side-effect free expression language evaluated against an applicant.
+//
+// Expressions support number, string and bool values, arithmetic (+ - * / %), comparisons, boolean
+// logic (&& || !) and a fixed set of functions. They are parsed and type-checked once by Compile into a
+// tree of typed closures, so evaluation does no parsing, map lookups or reflection.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ilivestrong/rules-engine/models"
+)
+
+const (
+	Number Type = iota
+	String
+	Bool
+)
+
+var (
+	ErrMissingValue   = errors.New("missing applicant value")
+	ErrDivisionByZero = errors.New("division by zero")
+)
+
+type (
+	Type int
+
+	Field struct {
+		Type Type
+		Get  func(applicant *models.Applicant) any
+	}
+
+	Program struct {
+		source string
+		fields []string
+		eval   boolFn
+	}
+
+	numFn  func(a *models.Applicant) (float64, error)
+	strFn  func(a *models.Applicant) (string, error)
+	boolFn func(a *models.Applicant) (bool, error)
+
+	compiled struct {
+		typ Type
+		num numFn
+		str strFn
+		b   boolFn
+	}
+
+	compiler struct {
+		fields map[string]Field
+		used   map[string]bool
+	}
+)
+
+func (t Type) String() string {
+	switch t {
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	default:
+		return "bool"
+	}
+}
+
+// Compile parses and type-checks src against the given fields, the expression must produce a bool.
+func Compile(src string, fields map[string]Field) (*Program, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compiler{fields: fields, used: make(map[string]bool)}
+	out, err := c.compile(tree)
+	if err != nil {
+		return nil, err
+	}
+	if out.typ != Bool {
+		return nil, fmt.Errorf("expression must be a bool, got %s", out.typ)
+	}
+
+	used := make([]string, 0, len(c.used))
+	for name := range c.used {
+		used = append(used, name)
+	}
+	sort.Strings(used)
+
+	return &Program{source: src, fields: used, eval: out.b}, nil
+}
+
+func (p *Program) Eval(applicant *models.Applicant) (bool, error) {
+	return p.eval(applicant)
+}
+
+func (p *Program) String() string {
+	return p.source
+}
+
+// Fields lists the applicant fields the expression reads, sorted by name.
+func (p *Program) Fields() []string {
+	return p.fields
+}
+
+func (c *compiler) compile(n node) (compiled, error) {
+	switch n := n.(type) {
+	case *numberLit:
+		v := n.value
+		return compiled{typ: Number, num: func(*models.Applicant) (float64, error) { return v, nil }}, nil
+	case *stringLit:
+		v := n.value
+		return compiled{typ: String, str: func(*models.Applicant) (string, error) { return v, nil }}, nil
+	case *boolLit:
+		v := n.value
+		return compiled{typ: Bool, b: func(*models.Applicant) (bool, error) { return v, nil }}, nil
+	case *ident:
+		return c.compileField(n)
+	case *unary:
+		return c.compileUnary(n)
+	case *binary:
+		return c.compileBinary(n)
+	case *call:
+		return c.compileCall(n)
+	default:
+		return compiled{}, fmt.Errorf("unsupported expression at %d", n.position())
+	}
+}
+
+func (c *compiler) compileField(n *ident) (compiled, error) {
+	field, ok := c.fields[n.name]
+	if !ok {
+		return compiled{}, fmt.Errorf("unknown field %q at %d", n.name, n.pos)
+	}
+	c.used[n.name] = true
+
+	get := field.Get
+	switch field.Type {
+	case Number:
+		return compiled{typ: Number, num: func(a *models.Applicant) (float64, error) {
+			if v, ok := get(a).(float64); ok {
+				return v, nil
+			}
+			return 0, ErrMissingValue
+		}}, nil
+	case String:
+		return compiled{typ: String, str: func(a *models.Applicant) (string, error) {
+			if v, ok := get(a).(string); ok {
+				return v, nil
+			}
+			return "", ErrMissingValue
+		}}, nil
+	default:
+		return compiled{typ: Bool, b: func(a *models.Applicant) (bool, error) {
+			if v, ok := get(a).(bool); ok {
+				return v, nil
+			}
+			return false, ErrMissingValue
+		}}, nil
+	}
+}
+
+func (c *compiler) compileUnary(n *unary) (compiled, error) {
+	operand, err := c.compile(n.operand)
+	if err != nil {
+		return compiled{}, err
+	}
+
+	if n.op == "!" {
+		if operand.typ != Bool {
+			return compiled{}, fmt.Errorf("operator '!' expects a bool, got %s at %d", operand.typ, n.pos)
+		}
+		f := operand.b
+		return compiled{typ: Bool, b: func(a *models.Applicant) (bool, error) {
+			v, err := f(a)
+			return !v, err
+		}}, nil
+	}
+
+	if operand.typ != Number {
+		return compiled{}, fmt.Errorf("operator '-' expects a number, got %s at %d", operand.typ, n.pos)
+	}
+	f := operand.num
+	return compiled{typ: Number, num: func(a *models.Applicant) (float64, error) {
+		v, err := f(a)
+		return -v, err
+	}}, nil
+}
+
+func (c *compiler) compileBinary(n *binary) (compiled, error) {
+	left, err := c.compile(n.left)
+	if err != nil {
+		return compiled{}, err
+	}
+	right, err := c.compile(n.right)
+	if err != nil {
+		return compiled{}, err
+	}
+	if left.typ != right.typ {
+		return compiled{}, fmt.Errorf("operator %q cannot combine %s and %s at %d", n.op, left.typ, right.typ, n.pos)
+	}
+
+	switch n.op {
+	case "&&", "||":
+		if left.typ != Bool {
+			return compiled{}, fmt.Errorf("operator %q expects bools, got %s at %d", n.op, left.typ, n.pos)
+		}
+		return logical(n.op, left.b, right.b), nil
+	case "==", "!=":
+		return equality(n.op, left, right), nil
+	case "<", "<=", ">", ">=":
+		switch left.typ {
+		case Number:
+			return ordering(n.op, left.num, right.num), nil
+		case String:
+			return ordering(n.op, left.str, right.str), nil
+		}
+		return compiled{}, fmt.Errorf("operator %q expects numbers or strings, got %s at %d", n.op, left.typ, n.pos)
+	case "+":
+		if left.typ == String {
+			l, r := left.str, right.str
+			return compiled{typ: String, str: func(a *models.Applicant) (string, error) {
+				lv, err := l(a)
+				if err != nil {
+					return "", err
+				}
+				rv, err := r(a)
+				return lv + rv, err
+			}}, nil
+		}
+		fallthrough
+	default:
+		if left.typ != Number {
+			return compiled{}, fmt.Errorf("operator %q expects numbers, got %s at %d", n.op, left.typ, n.pos)
+		}
+		return arithmetic(n.op, left.num, right.num), nil
+	}
+}
+
+func logical(op string, l, r boolFn) compiled {
+	return compiled{typ: Bool, b: func(a *models.Applicant) (bool, error) {
+		lv, err := l(a)
+		if err != nil {
+			return false, err
+		}
+		if (op == "&&" && !lv) || (op == "||" && lv) {
+			return lv, nil
+		}
+		return r(a)
+	}}
+}
+
+func equality(op string, l, r compiled) compiled {
+	var eq boolFn
+	switch l.typ {
+	case Number:
+		eq = pairwise(l.num, r.num, func(x, y float64) bool { return x == y })
+	case String:
+		eq = pairwise(l.str, r.str, func(x, y string) bool { return x == y })
+	default:
+		eq = pairwise(l.b, r.b, func(x, y bool) bool { return x == y })
+	}
+
+	negate := op == "!="
+	return compiled{typ: Bool, b: func(a *models.Applicant) (bool, error) {
+		v, err := eq(a)
+		return v != negate, err
+	}}
+}
+
+func ordering[T float64 | string](op string, l, r func(a *models.Applicant) (T, error)) compiled {
+	var cmp func(x, y T) bool
+	switch op {
+	case "<":
+		cmp = func(x, y T) bool { return x < y }
+	case "<=":
+		cmp = func(x, y T) bool { return x <= y }
+	case ">":
+		cmp = func(x, y T) bool { return x > y }
+	default:
+		cmp = func(x, y T) bool { return x >= y }
+	}
+	return compiled{typ: Bool, b: pairwise(l, r, cmp)}
+}
+
+func pairwise[T any](l, r func(a *models.Applicant) (T, error), f func(x, y T) bool) boolFn {
+	return func(a *models.Applicant) (bool, error) {
+		lv, err := l(a)
+		if err != nil {
+			return false, err
+		}
+		rv, err := r(a)
+		if err != nil {
+			return false, err
+		}
+		return f(lv, rv), nil
+	}
+}
+
+func arithmetic(op string, l, r numFn) compiled {
+	var f func(x, y float64) (float64, error)
+	switch op {
+	case "+":
+		f = func(x, y float64) (float64, error) { return x + y, nil }
+	case "-":
+		f = func(x, y float64) (float64, error) { return x - y, nil }
+	case "*":
+		f = func(x, y float64) (float64, error) { return x * y, nil }
+	case "/":
+		f = func(x, y float64) (float64, error) {
+			if y == 0 {
+				return 0, ErrDivisionByZero
+			}
+			return x / y, nil
+		}
+	default:
+		f = func(x, y float64) (float64, error) {
+			if y == 0 {
+				return 0, ErrDivisionByZero
+			}
+			return math.Mod(x, y), nil
+		}
+	}
+
+	return compiled{typ: Number, num: func(a *models.Applicant) (float64, error) {
+		lv, err := l(a)
+		if err != nil {
+			return 0, err
+		}
+		rv, err := r(a)
+		if err != nil {
+			return 0, err
+		}
+		return f(lv, rv)
+	}}
+}
+
+func (c *compiler) compileCall(n *call) (compiled, error) {
+	args := make([]compiled, len(n.args))
+	for i, arg := range n.args {
+		var err error
+		if args[i], err = c.compile(arg); err != nil {
+			return compiled{}, err
+		}
+	}
+
+	expect := func(types ...Type) error {
+		if len(args) != len(types) {
+			return fmt.Errorf("%s expects %d argument(s), got %d at %d", n.name, len(types), len(args), n.pos)
+		}
+		for i, t := range types {
+			if args[i].typ != t {
+				return fmt.Errorf("%s argument %d must be a %s, got %s at %d", n.name, i+1, t, args[i].typ, n.pos)
+			}
+		}
+		return nil
+	}
+
+	switch n.name {
+	case "len":
+		if err := expect(String); err != nil {
+			return compiled{}, err
+		}
+		s := args[0].str
+		return compiled{typ: Number, num: func(a *models.Applicant) (float64, error) {
+			v, err := s(a)
+			return float64(len(v)), err
+		}}, nil
+	case "lower", "upper", "trim":
+		if err := expect(String); err != nil {
+			return compiled{}, err
+		}
+		f := map[string]func(string) string{"lower": strings.ToLower, "upper": strings.ToUpper, "trim": strings.TrimSpace}[n.name]
+		s := args[0].str
+		return compiled{typ: String, str: func(a *models.Applicant) (string, error) {
+			v, err := s(a)
+			return f(v), err
+		}}, nil
+	case "startsWith", "endsWith", "contains":
+		if err := expect(String, String); err != nil {
+			return compiled{}, err
+		}
+		f := map[string]func(string, string) bool{"startsWith": strings.HasPrefix, "endsWith": strings.HasSuffix, "contains": strings.Contains}[n.name]
+		return compiled{typ: Bool, b: pairwise(args[0].str, args[1].str, f)}, nil
+	case "abs":
+		if err := expect(Number); err != nil {
+			return compiled{}, err
+		}
+		x := args[0].num
+		return compiled{typ: Number, num: func(a *models.Applicant) (float64, error) {
+			v, err := x(a)
+			return math.Abs(v), err
+		}}, nil
+	case "min", "max":
+		if err := expect(Number, Number); err != nil {
+			return compiled{}, err
+		}
+		f := map[string]func(float64, float64) float64{"min": math.Min, "max": math.Max}[n.name]
+		return arithmeticFn(args[0].num, args[1].num, f), nil
+	default:
+		return compiled{}, fmt.Errorf("unknown function %q at %d", n.name, n.pos)
+	}
+}
+
+func arithmeticFn(l, r numFn, f func(x, y float64) float64) compiled {
+	return compiled{typ: Number, num: func(a *models.Applicant) (float64, error) {
+		lv, err := l(a)
+		if err != nil {
+			return 0, err
+		}
+		rv, err := r(a)
+		return f(lv, rv), err
+	}}
+}
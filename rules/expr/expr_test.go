@@ -0,0 +1,136 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/ilivestrong/rules-engine/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFields = map[string]Field{
+	"income": {
+		Type: Number,
+		Get:  func(a *models.Applicant) any { return float64(a.Income) },
+	},
+	"number_of_credit_cards": {
+		Type: Number,
+		Get:  func(a *models.Applicant) any { return float64(a.NumberOfCreditCards) },
+	},
+	"politically_exposed": {
+		Type: Bool,
+		Get: func(a *models.Applicant) any {
+			if a.PoliticallyExposed == nil {
+				return nil
+			}
+			return *a.PoliticallyExposed
+		},
+	},
+	"phone_number": {
+		Type: String,
+		Get:  func(a *models.Applicant) any { return a.PhoneNumber },
+	},
+}
+
+func Test_Compile_Eval(t *testing.T) {
+	PPE := false
+	applicant := &models.Applicant{
+		Income:              120000,
+		NumberOfCreditCards: 2,
+		PoliticallyExposed:  &PPE,
+		PhoneNumber:         "202-324-0507",
+	}
+
+	tests := []struct {
+		name     string
+		src      string
+		expected bool
+		fields   []string
+		wantErr  error
+	}{
+		{
+			name:     "arithmetic with precedence",
+			src:      "income / (number_of_credit_cards + 1) > 30000",
+			expected: true,
+			fields:   []string{"income", "number_of_credit_cards"},
+		},
+		{
+			name:     "boolean logic",
+			src:      "!politically_exposed && (income >= 200000 || number_of_credit_cards <= 2)",
+			expected: true,
+			fields:   []string{"income", "number_of_credit_cards", "politically_exposed"},
+		},
+		{
+			name:     "string functions",
+			src:      `startsWith(phone_number, "2") && len(phone_number) == 12 && contains(lower("ABC"), 'b')`,
+			expected: true,
+			fields:   []string{"phone_number"},
+		},
+		{
+			name:     "unary minus and modulo",
+			src:      "-income % 7 == -(120000 % 7)",
+			expected: true,
+			fields:   []string{"income"},
+		},
+		{
+			name:    "division by zero",
+			src:     "income / (number_of_credit_cards - 2) > 1",
+			fields:  []string{"income", "number_of_credit_cards"},
+			wantErr: ErrDivisionByZero,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.src, testFields)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.fields, program.Fields())
+
+			got, err := program.Eval(applicant)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func Test_Eval_MissingValue(t *testing.T) {
+	program, err := Compile("politically_exposed == false", testFields)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = program.Eval(&models.Applicant{})
+	assert.ErrorIs(t, err, ErrMissingValue)
+}
+
+func Test_Compile_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		expectedErr string
+	}{
+		{name: "unknown field", src: "salary > 1", expectedErr: `unknown field "salary" at 0`},
+		{name: "unknown function", src: "sqrt(income) > 1", expectedErr: `unknown function "sqrt" at 0`},
+		{name: "type mismatch", src: "income > phone_number", expectedErr: `operator ">" cannot combine number and string at 7`},
+		{name: "not a bool", src: "income + 1", expectedErr: "expression must be a bool, got number"},
+		{name: "wrong argument type", src: "len(income) > 1", expectedErr: "len argument 1 must be a string, got number at 0"},
+		{name: "wrong argument count", src: `startsWith(phone_number) `, expectedErr: "startsWith expects 2 argument(s), got 1 at 0"},
+		{name: "unbalanced parens", src: "(income > 1", expectedErr: "expected ')' at 11"},
+		{name: "trailing tokens", src: "income > 1 2", expectedErr: `unexpected "2" at 11`},
+		{name: "unterminated string", src: `phone_number == "2`, expectedErr: "unterminated string at 16"},
+		{name: "unexpected character", src: "income > 1 & true", expectedErr: `unexpected character '&' at 11`},
+		{name: "empty", src: "", expectedErr: "unexpected end of expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src, testFields)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package expr
+
+import "fmt"
+
+type (
+	node interface {
+		position() int
+	}
+
+	numberLit struct {
+		value float64
+		pos   int
+	}
+	stringLit struct {
+		value string
+		pos   int
+	}
+	boolLit struct {
+		value bool
+		pos   int
+	}
+	ident struct {
+		name string
+		pos  int
+	}
+	unary struct {
+		op      string
+		operand node
+		pos     int
+	}
+	binary struct {
+		op          string
+		left, right node
+		pos         int
+	}
+	call struct {
+		name string
+		args []node
+		pos  int
+	}
+
+	parser struct {
+		tokens []token
+		pos    int
+	}
+)
+
+func (n *numberLit) position() int { return n.pos }
+func (n *stringLit) position() int { return n.pos }
+func (n *boolLit) position() int   { return n.pos }
+func (n *ident) position() int     { return n.pos }
+func (n *unary) position() int     { return n.pos }
+func (n *binary) position() int    { return n.pos }
+func (n *call) position() int      { return n.pos }
+
+// binding powers, higher binds tighter
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+func parse(src string) (node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseExpr(minPrec int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		prec, ok := precedence[tok.text]
+		if tok.kind != tokOp || !ok || prec <= minPrec {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseExpr(prec)
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: tok.text, left: left, right: right, pos: tok.pos}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if tok := p.peek(); tok.kind == tokOp && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{op: tok.text, operand: operand, pos: tok.pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return &numberLit{value: tok.num, pos: tok.pos}, nil
+	case tokString:
+		return &stringLit{value: tok.text, pos: tok.pos}, nil
+	case tokLParen:
+		n, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("expected ')' at %d", closing.pos)
+		}
+		return n, nil
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &boolLit{value: tok.text == "true", pos: tok.pos}, nil
+		}
+		if p.peek().kind != tokLParen {
+			return &ident{name: tok.text, pos: tok.pos}, nil
+		}
+		p.next()
+		return p.parseCall(tok)
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	c := &call{name: name.text, pos: name.pos}
+	if p.peek().kind == tokRParen {
+		p.next()
+		return c, nil
+	}
+
+	for {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, arg)
+
+		switch tok := p.next(); tok.kind {
+		case tokComma:
+			continue
+		case tokRParen:
+			return c, nil
+		default:
+			return nil, fmt.Errorf("expected ',' or ')' at %d", tok.pos)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ilivestrong/rules-engine/models"
+	"github.com/ilivestrong/rules-engine/rules/expr"
+)
+
+const (
+	RuleExpression = "Expression"
+)
+
+type ExpressionRule struct {
+	config  ExpressionConstraints
+	program *expr.Program
+}
+
+func (er *ExpressionRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	actual := make(map[string]any, len(er.program.Fields()))
+	for _, name := range er.program.Fields() {
+		actual[name] = applicantFields[name].get(&applicant)
+	}
+
+	passed, err := er.program.Eval(&applicant)
+	if err != nil {
+		return errored(err, er.config, actual)
+	}
+	return result(passed, er.config, actual)
+}
+
+func newExpressionRule(constraints map[string]any) (*ExpressionRule, error) {
+	config, err := decodeConstraints[ExpressionConstraints](constraints)
+	if err != nil {
+		return nil, err
+	}
+	if config.Expression == "" {
+		return nil, errors.New("missing expression")
+	}
+
+	program, err := expr.Compile(config.Expression, expressionFields())
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %v", config.Expression, err)
+	}
+
+	return &ExpressionRule{
+		config:  config,
+		program: program,
+	}, nil
+}
+
+func expressionFields() map[string]expr.Field {
+	fields := make(map[string]expr.Field, len(applicantFields))
+	for name, field := range applicantFields {
+		typ := expr.Bool
+		switch field.kind {
+		case numberField:
+			typ = expr.Number
+		case stringField:
+			typ = expr.String
+		}
+		fields[name] = expr.Field{Type: typ, Get: field.get}
+	}
+	return fields
+}
@@ -0,0 +1,86 @@
+package rules
+
+import "github.com/ilivestrong/rules-engine/models"
+
+const (
+	numberField fieldKind = iota
+	stringField
+	boolField
+)
+
+type (
+	fieldKind int
+
+	applicantField struct {
+		kind fieldKind
+		get  func(applicant *models.Applicant) any
+	}
+)
+
+// applicantFields maps the JSON names of models.Applicant to typed accessors, so configurable rules
+// can read any applicant attribute without reflection.
+var applicantFields = map[string]applicantField{
+	"income": {
+		kind: numberField,
+		get:  func(a *models.Applicant) any { return float64(a.Income) },
+	},
+	"number_of_credit_cards": {
+		kind: numberField,
+		get:  func(a *models.Applicant) any { return float64(a.NumberOfCreditCards) },
+	},
+	"age": {
+		kind: numberField,
+		get:  func(a *models.Applicant) any { return float64(a.Age) },
+	},
+	"politically_exposed": {
+		kind: boolField,
+		get: func(a *models.Applicant) any {
+			if a.PoliticallyExposed == nil {
+				return nil
+			}
+			return *a.PoliticallyExposed
+		},
+	},
+	"job_industry_code": {
+		kind: stringField,
+		get:  func(a *models.Applicant) any { return a.JobIndustryCode },
+	},
+	"phone_number": {
+		kind: stringField,
+		get:  func(a *models.Applicant) any { return a.PhoneNumber },
+	},
+}
+
+// ApplicantField reports whether name is the JSON name of an applicant attribute rules can read.
+func ApplicantField(name string) bool {
+	_, ok := applicantFields[name]
+	return ok
+}
+
+func (fk fieldKind) String() string {
+	switch fk {
+	case numberField:
+		return "number"
+	case stringField:
+		return "string"
+	default:
+		return "bool"
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,116 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ilivestrong/rules-engine/models"
+)
+
+const (
+	GroupAllOf  = "all_of"
+	GroupAnyOf  = "any_of"
+	GroupNoneOf = "none_of"
+)
+
+type (
+	GroupRule struct {
+		group    string
+		children []RuleHandler
+	}
+
+	modeKey struct{}
+)
+
+func (gr *GroupRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	evaluateAll := modeFromContext(ctx) == ModeEvaluateAll
+	res := RuleResult{Group: gr.group}
+
+	counts := make(map[Outcome]int)
+	for _, child := range gr.children {
+		childRes := child.Handle(ctx, &applicant)
+		res.Children = append(res.Children, childRes)
+		counts[childRes.Outcome]++
+
+		// stop as soon as the group outcome can no longer change
+		decided := (gr.group == GroupAllOf && childRes.Outcome == OutcomeFail) || (gr.group != GroupAllOf && childRes.Passed())
+		if decided && !evaluateAll {
+			break
+		}
+	}
+
+	// a definite answer wins, otherwise an errored child leaves the group undecided
+	switch {
+	case gr.group == GroupAllOf && counts[OutcomeFail] > 0:
+		res.Outcome = OutcomeFail
+	case gr.group == GroupAnyOf && counts[OutcomePass] > 0:
+		res.Outcome = OutcomePass
+	case gr.group == GroupNoneOf && counts[OutcomePass] > 0:
+		res.Outcome = OutcomeFail
+	case counts[OutcomeError] > 0:
+		res.Outcome = OutcomeError
+		res.Error = "a rule in the group errored"
+	case gr.group == GroupAnyOf:
+		res.Outcome = OutcomeFail
+	default:
+		res.Outcome = OutcomePass
+	}
+	return res
+}
+
+func isGroup(kind string) bool {
+	return kind == GroupAllOf || kind == GroupAnyOf || kind == GroupNoneOf
+}
+
+func newGroupRule(ruleInfo models.RuleInfo, deps Dependencies) (*GroupRule, error) {
+	if len(ruleInfo.Rules) == 0 {
+		return nil, errors.New("rule group has no rules")
+	}
+
+	group := &GroupRule{group: ruleInfo.RuleKind()}
+	for _, childInfo := range ruleInfo.Rules {
+		child, err := createRule(childInfo, deps)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", childInfo.Name, err)
+		}
+		handler, err := newRuleHandler(childInfo, child)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", childInfo.Name, err)
+		}
+		group.children = append(group.children, handler)
+	}
+	return group, nil
+}
+
+func withMode(ctx context.Context, mode Mode) context.Context {
+	return context.WithValue(ctx, modeKey{}, mode)
+}
+
+func modeFromContext(ctx context.Context) Mode {
+	if mode, ok := ctx.Value(modeKey{}).(Mode); ok {
+		return mode
+	}
+	return ModeShortCircuit
+}
+
+// collectRuleNames adds the names of the given rules and everything nested in their groups.
+func collectRuleNames(handlers []RuleHandler, names map[string]bool) {
+	for _, handler := range handlers {
+		names[handler.name] = true
+		if group, ok := handler.rule.(*GroupRule); ok {
+			collectRuleNames(group.children, names)
+		}
+	}
+}
+
+// appendRuleNames adds the names of the given rules in order, each group followed by the rules nested in it.
+func appendRuleNames(names []string, handlers []RuleHandler) []string {
+	for _, handler := range handlers {
+		names = append(names, handler.name)
+		if group, ok := handler.rule.(*GroupRule); ok {
+			names = appendRuleNames(names, group.children)
+		}
+	}
+	return names
+}
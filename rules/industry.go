@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ilivestrong/rules-engine/industry"
+	"github.com/ilivestrong/rules-engine/models"
+)
+
+const (
+	RuleIndustry = "Industry"
+
+	IndustryAllow = "allow"
+	IndustryBlock = "block"
+)
+
+// IndustryRule allows or blocks applicants by their job industry code. A code matches when it is one of the codes,
+// starts with one of the prefixes or falls in one of the ranges.
+type IndustryRule struct {
+	config   IndustryConstraints
+	ranges   []industry.Range
+	taxonomy industry.Taxonomy
+}
+
+func (ir *IndustryRule) Execute(ctx context.Context, applicant models.Applicant) RuleResult {
+	code, err := industry.Parse(applicant.JobIndustryCode)
+	if err != nil {
+		return errored(err, ir.config, applicant.JobIndustryCode)
+	}
+	code = ir.taxonomy.Describe(code)
+	return result(ir.matches(code) == (ir.config.Action == IndustryAllow), ir.config, code)
+}
+
+func (ir *IndustryRule) matches(code industry.Code) bool {
+	for _, c := range ir.config.Codes {
+		if code.Code == c {
+			return true
+		}
+	}
+	for _, prefix := range ir.config.Prefixes {
+		if code.HasPrefix(prefix) {
+			return true
+		}
+	}
+	for _, r := range ir.ranges {
+		if r.Contains(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func newIndustryRule(constraints map[string]any, deps Dependencies) (*IndustryRule, error) {
+	config, err := decodeConstraints[IndustryConstraints](constraints)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Codes)+len(config.Prefixes)+len(config.Ranges) == 0 {
+		return nil, errors.New("no codes, prefixes or ranges given")
+	}
+
+	for _, code := range config.Codes {
+		if !deps.Industries.Known(code) {
+			return nil, fmt.Errorf("unknown industry code %q", code)
+		}
+	}
+	ranges := make([]industry.Range, len(config.Ranges))
+	for i, raw := range config.Ranges {
+		if ranges[i], err = industry.ParseRange(raw); err != nil {
+			return nil, err
+		}
+	}
+	return &IndustryRule{config: config, ranges: ranges, taxonomy: deps.Industries}, nil
+}
@@ -7,6 +7,8 @@ import (
 
 	models "github.com/ilivestrong/rules-engine/models"
 	mock "github.com/stretchr/testify/mock"
+
+	rules "github.com/ilivestrong/rules-engine/rules"
 )
 
 // ApprovalRule is an autogenerated mock type for the ApprovalRule type
@@ -15,14 +17,14 @@ type ApprovalRule struct {
 }
 
 // Execute provides a mock function with given fields: ctx, applicant
-func (_m *ApprovalRule) Execute(ctx context.Context, applicant models.Applicant) bool {
+func (_m *ApprovalRule) Execute(ctx context.Context, applicant models.Applicant) rules.RuleResult {
 	ret := _m.Called(ctx, applicant)
 
-	var r0 bool
-	if rf, ok := ret.Get(0).(func(context.Context, models.Applicant) bool); ok {
+	var r0 rules.RuleResult
+	if rf, ok := ret.Get(0).(func(context.Context, models.Applicant) rules.RuleResult); ok {
 		r0 = rf(ctx, applicant)
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(rules.RuleResult)
 	}
 
 	return r0
@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const DefaultProduct = "standard"
+
+var ErrUnknownProduct = errors.New("unknown product")
+
+// Products holds one rules engine per card product, each loaded from its own rules and approved phone list.
+type Products struct {
+	defaultProduct string
+	engines        map[string]*RulesEngine
+}
+
+func NewProducts(defaultProduct string, engines map[string]*RulesEngine) (*Products, error) {
+	if _, ok := engines[defaultProduct]; !ok {
+		return nil, fmt.Errorf("%w: default product %q has no rules", ErrUnknownProduct, defaultProduct)
+	}
+	return &Products{defaultProduct: defaultProduct, engines: engines}, nil
+}
+
+// SingleProduct serves every request with engine, as the default product.
+func SingleProduct(engine *RulesEngine) *Products {
+	return &Products{
+		defaultProduct: DefaultProduct,
+		engines:        map[string]*RulesEngine{DefaultProduct: engine},
+	}
+}
+
+// Engine returns the rules engine of product and its name, an empty product selects the default one.
+func (p *Products) Engine(product string) (*RulesEngine, string, error) {
+	if product == "" {
+		product = p.defaultProduct
+	}
+	engine, ok := p.engines[product]
+	if !ok {
+		return nil, product, fmt.Errorf("%w %q, expected one of %s", ErrUnknownProduct, product, strings.Join(p.Names(), ", "))
+	}
+	return engine, product, nil
+}
+
+func (p *Products) Default() string {
+	return p.defaultProduct
+}
+
+// Names lists the configured products in alphabetical order.
+func (p *Products) Names() []string {
+	names := make([]string, 0, len(p.engines))
+	for name := range p.engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Reload reloads the rules of every product, a rejected config keeps that product's previous rules.
+func (p *Products) Reload() error {
+	var failed []string
+	for _, name := range p.Names() {
+		if err := p.engines[name].Reload(); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/industry"
+	"github.com/ilivestrong/rules-engine/risk"
+)
+
+type (
+	// Dependencies are the shared services handed to every rule factory.
+	Dependencies struct {
+		FileManager    helpers.FileManager
+		ApprovedPhones helpers.ApprovedPhoneStore
+		DenyList       helpers.DenyListStore
+		Industries     industry.Taxonomy
+		Risk           risk.RiskProvider
+	}
+
+	// RuleFactory builds a rule from the raw constraints of a rules.json entry.
+	RuleFactory func(constraints map[string]any, deps Dependencies) (ApprovalRule, error)
+
+	ruleKind struct {
+		factory RuleFactory
+		schema  constraintSchema
+	}
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]ruleKind)
+)
+
+func init() {
+	mustRegister(RuleMaster, MasterConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		config, err := decodeConstraints[MasterConstraints](constraints)
+		return &MasterRule{config: config, approvedPhones: deps.ApprovedPhones}, err
+	})
+	mustRegister(RuleDenyList, DenyListConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		return newDenyListRule(constraints, deps)
+	})
+	mustRegister(RuleIncome, IncomeConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		config, err := decodeConstraints[IncomeConstraints](constraints)
+		return &IncomeRule{config: config}, err
+	})
+	mustRegister(RuleAge, AgeConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		config, err := decodeConstraints[AgeConstraints](constraints)
+		return &AgeRule{config: config}, err
+	})
+	mustRegister(RuleNoOfCreditCards, NoOfCreditCardsConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		config, err := decodeConstraints[NoOfCreditCardsConstraints](constraints)
+		if deps.Risk == nil {
+			deps.Risk = risk.NewCalculatedProvider()
+		}
+		return &NoOfCreditCardsRule{config: config, risk: deps.Risk}, err
+	})
+	mustRegister(RulePoliticallyExposed, PoliticallyExposedConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		config, err := decodeConstraints[PoliticallyExposedConstraints](constraints)
+		return &PoliticallyExposedRule{config: config}, err
+	})
+	mustRegister(RulePhone, PhoneLocationConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		return newPhoneLocationRule(constraints)
+	})
+	mustRegister(RuleIndustry, IndustryConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		return newIndustryRule(constraints, deps)
+	})
+	mustRegister(RuleCompare, CompareConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		return newCompareRule(constraints)
+	})
+	mustRegister(RuleExpression, ExpressionConstraints{}, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		return newExpressionRule(constraints)
+	})
+
+	// groups are built by createRule itself, they are registered for their schema and to reserve the names
+	for _, group := range []string{GroupAllOf, GroupAnyOf, GroupNoneOf} {
+		mustRegister(group, GroupConstraints{}, nil)
+	}
+}
+
+// Register adds a rule kind that rules.json can refer to with "kind". Its constraints are passed to the factory
+// as they are in the config, without schema validation.
+func Register(kind string, factory RuleFactory) error {
+	if factory == nil {
+		return fmt.Errorf("rule kind %q needs a factory", kind)
+	}
+	return register(kind, nil, factory)
+}
+
+// RegisterTyped adds a rule kind whose constraints are validated against, and decoded into, T.
+// T is a struct tagged like the built-in constraint types.
+func RegisterTyped[T any](kind string, build func(config T, deps Dependencies) (ApprovalRule, error)) error {
+	if build == nil {
+		return fmt.Errorf("rule kind %q needs a factory", kind)
+	}
+	var zero T
+	return register(kind, zero, func(constraints map[string]any, deps Dependencies) (ApprovalRule, error) {
+		config, err := decodeConstraints[T](constraints)
+		if err != nil {
+			return nil, err
+		}
+		return build(config, deps)
+	})
+}
+
+// DecodeConstraints fills a typed constraint struct from raw constraints, for factories added with Register.
+func DecodeConstraints[T any](constraints map[string]any) (T, error) {
+	return decodeConstraints[T](constraints)
+}
+
+func register(kind string, constraints any, factory RuleFactory) error {
+	if kind == "" {
+		return errors.New("rule kind must not be empty")
+	}
+
+	entry := ruleKind{factory: factory}
+	if constraints != nil {
+		typ := reflect.TypeOf(constraints)
+		if typ.Kind() != reflect.Struct {
+			return fmt.Errorf("constraints of rule kind %q must be a struct, got %s", kind, typ)
+		}
+		schema, err := schemaOf(typ)
+		if err != nil {
+			return fmt.Errorf("rule kind %q: %v", kind, err)
+		}
+		if err := applyDefaults(reflect.New(typ).Interface()); err != nil {
+			return fmt.Errorf("rule kind %q: %v", kind, err)
+		}
+		entry.schema = schema
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[kind]; ok {
+		return fmt.Errorf("rule kind %q is already registered", kind)
+	}
+	registry[kind] = entry
+	return nil
+}
+
+func mustRegister(kind string, constraints any, factory RuleFactory) {
+	if err := register(kind, constraints, factory); err != nil {
+		panic(err)
+	}
+}
+
+func lookupKind(kind string) (ruleKind, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	entry, ok := registry[kind]
+	return entry, ok
+}
+
+func registeredKinds() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	kinds := make([]string, 0, len(registry))
+	for kind := range registry {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -0,0 +1,195 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ilivestrong/rules-engine/models"
+)
+
+const (
+	numberType constraintType = iota
+	integerType
+	stringType
+	boolType
+	stringListType
+	anyType
+)
+
+type (
+	constraintType int
+
+	constraintSpec struct {
+		typ      constraintType
+		required bool
+		min      *float64
+		max      *float64
+		oneOf    []string
+		pattern  *regexp.Regexp
+		def      string
+	}
+
+	constraintSchema map[string]constraintSpec
+
+	ConfigError struct {
+		Problems []string
+	}
+)
+
+func (ce *ConfigError) Error() string {
+	return "invalid rules config: " + strings.Join(ce.Problems, "; ")
+}
+
+func (ct constraintType) String() string {
+	return [...]string{"a number", "an integer", "a string", "a bool", "a list of strings", "a value"}[ct]
+}
+
+// validateConfig checks every rule against the constraint schema of its kind and reports all problems at once,
+// each prefixed with the path of the offending value.
+func validateConfig(config *models.RulesConfig) error {
+	var problems []string
+	validateRules("rules", config.Rules, false, &problems)
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// validateRules checks ruleInfos at path, nested rules belong to a group which decides their outcome on its own.
+func validateRules(path string, ruleInfos []models.RuleInfo, nested bool, problems *[]string) {
+	// the DenyList and Master rules run before all others, so there is one of each at most and only at the top level
+	special := map[string]bool{}
+	for i, ruleInfo := range ruleInfos {
+		rulePath := fmt.Sprintf("%s[%d]", path, i)
+		if ruleInfo.Name == "" {
+			*problems = append(*problems, rulePath+".rule_name: is required")
+		} else {
+			rulePath = fmt.Sprintf("%s(%s)", rulePath, ruleInfo.Name)
+		}
+		if nested && ruleInfo.OnFail != "" {
+			*problems = append(*problems, rulePath+".on_fail: is only allowed on top level rules, set it on the group")
+		}
+
+		kind := ruleInfo.RuleKind()
+		entry, ok := lookupKind(kind)
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s.kind: unknown rule kind %q", rulePath, kind))
+			continue
+		}
+		if kind == RuleMaster || kind == RuleDenyList {
+			switch {
+			case nested:
+				*problems = append(*problems, fmt.Sprintf("%s.kind: a %s rule can't be in a group", rulePath, kind))
+			case special[kind]:
+				*problems = append(*problems, fmt.Sprintf("%s.kind: only one %s rule is allowed", rulePath, kind))
+			}
+			special[kind] = true
+		}
+
+		if isGroup(kind) {
+			if len(ruleInfo.Rules) == 0 {
+				*problems = append(*problems, rulePath+".rules: a rule group needs at least one rule")
+			}
+			validateRules(rulePath+".rules", ruleInfo.Rules, true, problems)
+		} else if len(ruleInfo.Rules) > 0 {
+			*problems = append(*problems, rulePath+".rules: only rule groups can have nested rules")
+		}
+
+		if entry.schema != nil {
+			entry.schema.validate(rulePath+".constraints", ruleInfo.Constraints, problems)
+		}
+	}
+}
+
+func (schema constraintSchema) validate(path string, constraints map[string]any, problems *[]string) {
+	keys := make([]string, 0, len(constraints))
+	for key := range constraints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		spec, ok := schema[key]
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s.%s: unknown constraint", path, key))
+			continue
+		}
+		if err := spec.check(constraints[key]); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s.%s: %v", path, key, err))
+		}
+	}
+
+	required := make([]string, 0)
+	for key, spec := range schema {
+		if _, ok := constraints[key]; spec.required && !ok {
+			required = append(required, key)
+		}
+	}
+	sort.Strings(required)
+	for _, key := range required {
+		*problems = append(*problems, fmt.Sprintf("%s.%s: is required", path, key))
+	}
+}
+
+func (spec constraintSpec) check(value any) error {
+	switch spec.typ {
+	case numberType, integerType:
+		n, ok := toFloat(value)
+		if !ok || (spec.typ == integerType && n != math.Trunc(n)) {
+			return fmt.Errorf("must be %s, got %v", spec.typ, value)
+		}
+		if spec.min != nil && n < *spec.min {
+			return fmt.Errorf("must be at least %v, got %v", *spec.min, n)
+		}
+		if spec.max != nil && n > *spec.max {
+			return fmt.Errorf("must be at most %v, got %v", *spec.max, n)
+		}
+	case stringType:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be %s, got %v", spec.typ, value)
+		}
+		if len(spec.oneOf) > 0 && !contains(spec.oneOf, s) {
+			return fmt.Errorf("must be one of %s, got %q", strings.Join(spec.oneOf, ", "), s)
+		}
+	case boolType:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be %s, got %v", spec.typ, value)
+		}
+	case stringListType:
+		list, err := toList(value)
+		if err != nil {
+			return fmt.Errorf("must be %s, got %v", spec.typ, value)
+		}
+		for i, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("item %d must be a string, got %v", i, item)
+			}
+			if spec.pattern != nil && !spec.pattern.MatchString(s) {
+				return fmt.Errorf("item %d must match %s, got %q", i, spec.pattern, s)
+			}
+		}
+	case anyType:
+		if value == nil {
+			return fmt.Errorf("must be %s", spec.typ)
+		}
+	}
+	return nil
+}
+
+func bound(v float64) *float64 {
+	return &v
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ilivestrong/rules-engine/models"
+)
+
+const maxHistory = 50
+
+var ErrUnknownVersion = errors.New("unknown rules version")
+
+type (
+	RuleSetVersion struct {
+		ID       string              `json:"id"`
+		Label    string              `json:"label,omitempty"`
+		LoadedAt time.Time           `json:"loaded_at"`
+		Active   bool                `json:"active"`
+		Config   *models.RulesConfig `json:"config,omitempty"`
+	}
+
+	EngineOption func(*RulesEngine)
+)
+
+// WithHistoryDir keeps a copy of every loaded rule set in dir, so earlier versions can still be rolled back
+// to after a restart.
+func WithHistoryDir(dir string) EngineOption {
+	return func(re *RulesEngine) {
+		re.historyDir = dir
+	}
+}
+
+func newVersion(config *models.RulesConfig) (*RuleSetVersion, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash rules: %v", err)
+	}
+	sum := sha256.Sum256(data)
+
+	return &RuleSetVersion{
+		ID:       hex.EncodeToString(sum[:])[:12],
+		Label:    config.Label,
+		LoadedAt: time.Now().UTC(),
+		Config:   config,
+	}, nil
+}
+
+// Version is the rule set currently used by Verify.
+func (re *RulesEngine) Version() RuleSetVersion {
+	version := *re.current().version
+	version.Active = true
+	version.Config = nil
+	return version
+}
+
+// Versions lists the known rule sets, most recently loaded first.
+func (re *RulesEngine) Versions() []RuleSetVersion {
+	re.reloadMu.Lock()
+	defer re.reloadMu.Unlock()
+
+	activeID := re.current().version.ID
+	versions := make([]RuleSetVersion, 0, len(re.history))
+	for i := len(re.history) - 1; i >= 0; i-- {
+		version := *re.history[i]
+		version.Active = version.ID == activeID
+		version.Config = nil
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// Rollback activates a previously loaded rule set, looked up by id or label. The rules file itself is not
+// changed, so the next change to it is loaded as usual.
+func (re *RulesEngine) Rollback(idOrLabel string) (RuleSetVersion, error) {
+	re.reloadMu.Lock()
+	defer re.reloadMu.Unlock()
+
+	var target *RuleSetVersion
+	for i := len(re.history) - 1; i >= 0 && target == nil; i-- {
+		if re.history[i].ID == idOrLabel || (re.history[i].Label != "" && re.history[i].Label == idOrLabel) {
+			target = re.history[i]
+		}
+	}
+	if target == nil {
+		return RuleSetVersion{}, fmt.Errorf("%w: %s", ErrUnknownVersion, idOrLabel)
+	}
+
+	deps, err := re.dependencies()
+	if err != nil {
+		return RuleSetVersion{}, fmt.Errorf("failed to rebuild rules version %s: %v", target.ID, err)
+	}
+	rs, err := buildRuleSet(target.Config, deps)
+	if err != nil {
+		return RuleSetVersion{}, fmt.Errorf("failed to rebuild rules version %s: %v", target.ID, err)
+	}
+	rs.version = target
+	re.active.Store(rs)
+
+	return re.Version(), nil
+}
+
+// activate swaps in rs and records its version, callers must hold reloadMu.
+func (re *RulesEngine) activate(rs *ruleSet) {
+	for _, known := range re.history {
+		if known.ID == rs.version.ID {
+			rs.version = known
+			re.active.Store(rs)
+			return
+		}
+	}
+
+	re.active.Store(rs)
+	re.history = append(re.history, rs.version)
+	if len(re.history) > maxHistory {
+		re.history = re.history[len(re.history)-maxHistory:]
+	}
+	re.saveVersion(rs.version)
+}
+
+func (re *RulesEngine) saveVersion(version *RuleSetVersion) {
+	if re.historyDir == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(version, "", "    ")
+	if err == nil {
+		err = os.MkdirAll(re.historyDir, 0755)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(re.historyDir, version.ID+".json"), data, 0644)
+	}
+	if err != nil {
+		log.Printf("failed to save rules version %s: %v\n", version.ID, err)
+	}
+}
+
+// loadHistory reads the versions saved by earlier runs, oldest first.
+func (re *RulesEngine) loadHistory() {
+	if re.historyDir == "" {
+		return
+	}
+
+	files, err := ioutil.ReadDir(re.historyDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("failed to read rules history: %v\n", err)
+		}
+		return
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(re.historyDir, file.Name()))
+		if err != nil {
+			log.Printf("failed to read rules version %s: %v\n", file.Name(), err)
+			continue
+		}
+		var version RuleSetVersion
+		if err := json.Unmarshal(data, &version); err != nil || version.Config == nil {
+			log.Printf("invalid rules version %s, skipping\n", file.Name())
+			continue
+		}
+		version.Active = false
+		re.history = append(re.history, &version)
+	}
+
+	sort.SliceStable(re.history, func(i, j int) bool {
+		return re.history[i].LoadedAt.Before(re.history[j].LoadedAt)
+	})
+	if len(re.history) > maxHistory {
+		re.history = re.history[len(re.history)-maxHistory:]
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/rules"
+	"github.com/ilivestrong/rules-engine/simulation"
+)
+
+// simulate runs an applicants file through a rules config offline. Per-record decisions are written as JSON lines
+// to stdout (or --output) and the aggregate report goes to stderr, so both can be kept apart.
+func simulate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	rulesPath := flags.String("rules", "rules/rules.json", "rules config to evaluate")
+	inputPath := flags.String("input", "", "JSON array of applicants, e.g. 1000-records.json")
+	approvedPhones := flags.String("approved-phones", "", "approved phone list (default: approved-phone-list.json next to --rules)")
+	mode := flags.String("mode", rules.ModeEvaluateAll, "evaluation mode, evaluate_all counts every failing rule")
+	outputPath := flags.String("output", "", "file for per-record decisions (default: stdout)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *inputPath == "" || !rules.ValidMode(*mode) {
+		fmt.Fprintln(stderr, "usage: rules-engine simulate --rules rules.json --input 1000-records.json [--mode evaluate_all]")
+		return 2
+	}
+
+	engine, err := offlineEngine(*rulesPath, *approvedPhones)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	applicants, err := simulation.LoadApplicants(*inputPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	out := stdout
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to create output: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	results, report := simulation.Run(context.Background(), engine, applicants, rules.WithMode(*mode))
+	encoder := json.NewEncoder(out)
+	for _, res := range results {
+		if err := encoder.Encode(res); err != nil {
+			fmt.Fprintf(stderr, "failed to write decisions: %v\n", err)
+			return 1
+		}
+	}
+	report.Print(stderr)
+	return 0
+}
+
+// diffRules evaluates an applicants file with two rules configs and reports the applicants whose status flips.
+// With --fail-on-flips it exits with status 3 when anyone flips, so it can gate a review of rules.json changes.
+func diffRules(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	beforePath := flags.String("before", "", "current rules config")
+	afterPath := flags.String("after", "", "changed rules config")
+	inputPath := flags.String("input", "", "JSON array of applicants, e.g. 1000-records.json")
+	approvedPhones := flags.String("approved-phones", "", "approved phone list (default: approved-phone-list.json next to each config)")
+	mode := flags.String("mode", rules.ModeEvaluateAll, "evaluation mode, evaluate_all finds every responsible rule")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	failOnFlips := flags.Bool("fail-on-flips", false, "exit with status 3 when any applicant flips")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *beforePath == "" || *afterPath == "" || *inputPath == "" || !rules.ValidMode(*mode) {
+		fmt.Fprintln(stderr, "usage: rules-engine diff --before old.json --after new.json --input 1000-records.json [--json] [--fail-on-flips]")
+		return 2
+	}
+
+	before, err := offlineEngine(*beforePath, *approvedPhones)
+	if err != nil {
+		fmt.Fprintf(stderr, "before: %v\n", err)
+		return 1
+	}
+	after, err := offlineEngine(*afterPath, *approvedPhones)
+	if err != nil {
+		fmt.Fprintf(stderr, "after: %v\n", err)
+		return 1
+	}
+	applicants, err := simulation.LoadApplicants(*inputPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	report := simulation.Diff(context.Background(), before, after, applicants, rules.WithMode(*mode))
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "    ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "failed to write report: %v\n", err)
+			return 1
+		}
+	} else {
+		report.Print(stdout)
+	}
+
+	if *failOnFlips && len(report.Flips) > 0 {
+		return 3
+	}
+	return 0
+}
+
+// offlineEngine loads a rules config from any path, with the approved phone list next to it by default and the
+// deny list and industry taxonomy always next to it.
+func offlineEngine(rulesPath, approvedPhones string) (*rules.RulesEngine, error) {
+	if approvedPhones == "" {
+		approvedPhones = filepath.Join(filepath.Dir(rulesPath), "approved-phone-list.json")
+	}
+	fileManager := helpers.NewFileManagerWithPaths(rulesPath, approvedPhones)
+	return rules.NewRulesEngine(
+		fileManager,
+		rules.WithApprovedPhones(helpers.NewFileApprovedPhoneStore(approvedPhones)),
+		rules.WithDenyList(helpers.NewFileDenyListStore(fileManager.DenyListPath())),
+		rules.WithIndustryTaxonomy(fileManager.IndustryTaxonomyPath()),
+	)
+}
@@ -0,0 +1,145 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ilivestrong/rules-engine/models"
+	"github.com/ilivestrong/rules-engine/rules"
+)
+
+type (
+	// Flip is an applicant whose status differs between two rule sets. Rules are the ones responsible: rules that
+	// started failing when the status got stricter, rules that stopped failing when it got more lenient.
+	Flip struct {
+		Index  int          `json:"index"`
+		Before rules.Status `json:"before"`
+		After  rules.Status `json:"after"`
+		Rules  []string     `json:"rules"`
+	}
+
+	DiffReport struct {
+		Before      *Report        `json:"before"`
+		After       *Report        `json:"after"`
+		Records     int            `json:"records"`
+		Invalid     int            `json:"invalid"`
+		Unchanged   int            `json:"unchanged"`
+		Transitions map[string]int `json:"transitions"`
+		RuleFlips   map[string]int `json:"rule_flips"`
+		Flips       []Flip         `json:"flips"`
+	}
+)
+
+// Diff evaluates applicants with both engines and reports who flips between them. Pass rules.ModeEvaluateAll so
+// every responsible rule is found, not only the first failing one.
+func Diff(ctx context.Context, before, after *rules.RulesEngine, applicants []models.Applicant, opts ...rules.VerifyOption) *DiffReport {
+	beforeResults, beforeReport := Run(ctx, before, applicants, opts...)
+	afterResults, afterReport := Run(ctx, after, applicants, opts...)
+
+	diff := &DiffReport{
+		Before:      beforeReport,
+		After:       afterReport,
+		Records:     len(applicants),
+		Invalid:     beforeReport.Invalid,
+		Transitions: make(map[string]int),
+		RuleFlips:   make(map[string]int),
+		Flips:       make([]Flip, 0),
+	}
+
+	for i := range beforeResults {
+		from, to := beforeResults[i], afterResults[i]
+		if from.Status == StatusInvalid {
+			continue
+		}
+		if from.Status == to.Status {
+			diff.Unchanged++
+			continue
+		}
+
+		responsible := difference(blamed(to), blamed(from))
+		if !rules.Stricter(to.Status, from.Status) {
+			responsible = difference(blamed(from), blamed(to))
+		}
+		if from.Bypassed != to.Bypassed {
+			bypassRule := afterReport.BypassRule
+			if from.Bypassed {
+				bypassRule = beforeReport.BypassRule
+			}
+			responsible = append([]string{bypassRule}, responsible...)
+		}
+
+		diff.Transitions[transition(from.Status, to.Status)]++
+		for _, name := range responsible {
+			diff.RuleFlips[name]++
+		}
+		diff.Flips = append(diff.Flips, Flip{Index: i, Before: from.Status, After: to.Status, Rules: responsible})
+	}
+	return diff
+}
+
+// Print writes the summary counts followed by every flip, in a form that can be pasted into a review.
+func (d *DiffReport) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "before\t%s, approval rate %.2f%%\n", d.Before.versionLabel(), d.Before.ApprovalRate*100)
+	fmt.Fprintf(tw, "after\t%s, approval rate %.2f%%\n", d.After.versionLabel(), d.After.ApprovalRate*100)
+	fmt.Fprintf(tw, "records\t%d (%d invalid)\n", d.Records, d.Invalid)
+	fmt.Fprintf(tw, "unchanged\t%d\n", d.Unchanged)
+	fmt.Fprintf(tw, "flipped\t%d\n", len(d.Flips))
+
+	for _, key := range sortedKeys(d.Transitions) {
+		fmt.Fprintf(tw, "  %s\t%d\n", key, d.Transitions[key])
+	}
+	if len(d.RuleFlips) > 0 {
+		fmt.Fprintln(tw, "\nrule\tflips")
+		for _, name := range sortedKeys(d.RuleFlips) {
+			fmt.Fprintf(tw, "%s\t%d\n", name, d.RuleFlips[name])
+		}
+	}
+	if len(d.Flips) > 0 {
+		fmt.Fprintln(tw, "\nrecord\tchange\trules")
+		for _, flip := range d.Flips {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", flip.Index, transition(flip.Before, flip.After), strings.Join(flip.Rules, ", "))
+		}
+	}
+	tw.Flush()
+}
+
+func transition(from, to rules.Status) string {
+	return from + " -> " + to
+}
+
+// blamed lists every rule that kept an applicant from a clean approval.
+func blamed(res RecordResult) []string {
+	if res.Bypassed {
+		return nil
+	}
+	names := append(append([]string(nil), res.FailedRules...), res.ReferredRules...)
+	return append(names, res.ErroredRules...)
+}
+
+func difference(names, minus []string) []string {
+	excluded := make(map[string]bool, len(minus))
+	for _, name := range minus {
+		excluded[name] = true
+	}
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if !excluded[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,184 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ilivestrong/rules-engine/models"
+	"github.com/ilivestrong/rules-engine/rules"
+)
+
+// StatusInvalid marks records the API would reject before running any rule.
+const StatusInvalid = "invalid"
+
+type (
+	RecordResult struct {
+		Index         int      `json:"index"`
+		Status        string   `json:"status"`
+		Error         string   `json:"error,omitempty"`
+		Bypassed      bool     `json:"bypassed,omitempty"`
+		FailedRules   []string `json:"failed_rules,omitempty"`
+		ReferredRules []string `json:"referred_rules,omitempty"`
+		ErroredRules  []string `json:"errored_rules,omitempty"`
+	}
+
+	Report struct {
+		RulesVersion string         `json:"rules_version"`
+		RulesLabel   string         `json:"rules_label,omitempty"`
+		Mode         rules.Mode     `json:"mode"`
+		Records      int            `json:"records"`
+		Invalid      int            `json:"invalid"`
+		Statuses     map[string]int `json:"statuses"`
+		ApprovalRate float64        `json:"approval_rate"`
+		Bypassed     int            `json:"bypassed"`
+		BypassRule   string         `json:"bypass_rule,omitempty"`
+		Rules        []string       `json:"rules"`
+		RuleFailures map[string]int `json:"rule_failures"`
+		RuleErrors   map[string]int `json:"rule_errors"`
+		NeverFired   []string       `json:"never_fired"`
+	}
+)
+
+// LoadApplicants reads a JSON array of applicants, in the format of 1000-records.json.
+func LoadApplicants(path string) ([]models.Applicant, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applicants: %v", err)
+	}
+
+	var applicants []models.Applicant
+	if err := json.Unmarshal(data, &applicants); err != nil {
+		return nil, fmt.Errorf("invalid applicants file %s: %v", path, err)
+	}
+	return applicants, nil
+}
+
+// Run evaluates every applicant with engine and aggregates the decisions. A rule fires when it fails or refers an
+// applicant, the Master rule fires when it bypasses one. Rules nested in groups are counted too, see countNested. Use
+// rules.ModeEvaluateAll to count rules behind the first failure.
+func Run(ctx context.Context, engine *rules.RulesEngine, applicants []models.Applicant, opts ...rules.VerifyOption) ([]RecordResult, *Report) {
+	version := engine.Version()
+	results := make([]RecordResult, 0, len(applicants))
+	report := &Report{
+		RulesVersion: version.ID,
+		RulesLabel:   version.Label,
+		Records:      len(applicants),
+		Statuses:     make(map[string]int),
+		RuleFailures: make(map[string]int),
+		RuleErrors:   make(map[string]int),
+	}
+
+	for i := range applicants {
+		applicant := applicants[i]
+		if err := applicant.Validate(); err != nil {
+			results = append(results, RecordResult{Index: i, Status: StatusInvalid, Error: err.Error()})
+			report.Invalid++
+			continue
+		}
+
+		decision := engine.Verify(ctx, &applicant, opts...)
+		report.Mode = decision.Mode
+		report.Statuses[decision.Status]++
+		if decision.Bypassed {
+			report.Bypassed++
+		}
+		for _, name := range decision.FailedRules {
+			report.RuleFailures[name]++
+		}
+		for _, name := range decision.ReferredRules {
+			report.RuleFailures[name]++
+		}
+		for _, name := range decision.ErroredRules {
+			report.RuleErrors[name]++
+		}
+		for _, res := range decision.Rules {
+			countNested(res, report)
+		}
+
+		results = append(results, RecordResult{
+			Index:         i,
+			Status:        decision.Status,
+			Bypassed:      decision.Bypassed,
+			FailedRules:   decision.FailedRules,
+			ReferredRules: decision.ReferredRules,
+			ErroredRules:  decision.ErroredRules,
+		})
+	}
+
+	if evaluated := report.Records - report.Invalid; evaluated > 0 {
+		report.ApprovalRate = float64(report.Statuses[rules.StatusApproved]) / float64(evaluated)
+	}
+
+	report.Rules = engine.AllRuleNames()
+	report.BypassRule = engine.BypassRuleName()
+	report.NeverFired = make([]string, 0)
+	for _, name := range report.Rules {
+		fired := report.RuleFailures[name] > 0
+		if name == report.BypassRule {
+			fired = report.Bypassed > 0
+		}
+		if !fired {
+			report.NeverFired = append(report.NeverFired, name)
+		}
+	}
+	return results, report
+}
+
+// countNested counts the rules nested in a group result. A nested rule fires when it counts against the applicant,
+// it fails, or it passes inside none_of.
+func countNested(group rules.RuleResult, report *Report) {
+	for _, child := range group.Children {
+		fired := child.Outcome == rules.OutcomeFail
+		if group.Group == rules.GroupNoneOf {
+			fired = child.Passed()
+		}
+		if fired {
+			report.RuleFailures[child.Name]++
+		}
+		if child.Outcome == rules.OutcomeError {
+			report.RuleErrors[child.Name]++
+		}
+		countNested(child, report)
+	}
+}
+
+// Print writes the report as an aligned summary for the terminal.
+func (r *Report) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "rules\t%s, %s\n", r.versionLabel(), r.Mode)
+	fmt.Fprintf(tw, "records\t%d\n", r.Records)
+	fmt.Fprintf(tw, "invalid\t%d\n", r.Invalid)
+	fmt.Fprintf(tw, "approval rate\t%.2f%%\n", r.ApprovalRate*100)
+	for _, status := range []string{rules.StatusApproved, rules.StatusDeclined, rules.StatusReferred, rules.StatusError} {
+		fmt.Fprintf(tw, "%s\t%d\n", status, r.Statuses[status])
+	}
+	fmt.Fprintf(tw, "bypassed\t%d\n", r.Bypassed)
+
+	fmt.Fprintln(tw, "\nrule\tfired\terrors")
+	for _, name := range r.Rules {
+		fired := r.RuleFailures[name]
+		if name == r.BypassRule {
+			fired = r.Bypassed
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", name, fired, r.RuleErrors[name])
+	}
+
+	neverFired := "none"
+	if len(r.NeverFired) > 0 {
+		neverFired = strings.Join(r.NeverFired, ", ")
+	}
+	fmt.Fprintf(tw, "\nnever fired\t%s\n", neverFired)
+	tw.Flush()
+}
+
+func (r *Report) versionLabel() string {
+	if r.RulesLabel == "" {
+		return r.RulesVersion
+	}
+	return fmt.Sprintf("%s (%s)", r.RulesVersion, r.RulesLabel)
+}
@@ -0,0 +1,173 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ilivestrong/rules-engine/helpers"
+	"github.com/ilivestrong/rules-engine/helpers/mocks"
+	"github.com/ilivestrong/rules-engine/models"
+	"github.com/ilivestrong/rules-engine/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Run(t *testing.T) {
+	PPE := false
+	fileManager := mocks.NewFileManager(t)
+	fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+		RequiredRules: []string{},
+		Rules: []models.RuleInfo{
+			{Name: rules.RuleMaster, Constraints: map[string]any{"check_approved_phones": true}},
+			{Name: rules.RuleIncome, Constraints: map[string]any{"minimum_salary": 100000}},
+			{Name: rules.RuleAge, Constraints: map[string]any{"min_age_allowed": 18}},
+		},
+	}, nil)
+
+	engine, err := rules.NewRulesEngine(fileManager)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	applicants := []models.Applicant{
+		{Income: 120000, Age: 30, PoliticallyExposed: &PPE},
+		{Income: 90000, Age: 30, PoliticallyExposed: &PPE},
+		{Income: 90000, Age: 10, PoliticallyExposed: &PPE},
+		{Income: 120000, Age: 30},
+	}
+
+	t.Run("evaluate all", func(t *testing.T) {
+		results, report := Run(context.Background(), engine, applicants, rules.WithMode(rules.ModeEvaluateAll))
+
+		assert.Equal(t, []RecordResult{
+			{Index: 0, Status: rules.StatusApproved},
+			{Index: 1, Status: rules.StatusDeclined, FailedRules: []string{rules.RuleIncome}},
+			{Index: 2, Status: rules.StatusDeclined, FailedRules: []string{rules.RuleIncome, rules.RuleAge}},
+			{Index: 3, Status: StatusInvalid, Error: "politically_exposed is required"},
+		}, results)
+		assert.Equal(t, 4, report.Records)
+		assert.Equal(t, 1, report.Invalid)
+		assert.Equal(t, map[string]int{rules.StatusApproved: 1, rules.StatusDeclined: 2}, report.Statuses)
+		assert.InDelta(t, 1.0/3, report.ApprovalRate, 0.0001)
+		assert.Equal(t, map[string]int{rules.RuleIncome: 2, rules.RuleAge: 1}, report.RuleFailures)
+		assert.Equal(t, []string{rules.RuleMaster}, report.NeverFired)
+		assert.Equal(t, engine.Version().ID, report.RulesVersion)
+	})
+
+	t.Run("short circuit hides later failures", func(t *testing.T) {
+		_, report := Run(context.Background(), engine, applicants, rules.WithMode(rules.ModeShortCircuit))
+
+		assert.Equal(t, map[string]int{rules.RuleIncome: 2}, report.RuleFailures)
+		assert.Equal(t, []string{rules.RuleMaster, rules.RuleAge}, report.NeverFired)
+	})
+}
+
+func Test_Run_NestedRules(t *testing.T) {
+	PPE := false
+	fileManager := mocks.NewFileManager(t)
+	fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+		RequiredRules: []string{},
+		Rules: []models.RuleInfo{
+			{Name: rules.RuleIncome, Constraints: map[string]any{"minimum_salary": 100000}},
+			{Name: "Eligible", Kind: rules.GroupAllOf, Rules: []models.RuleInfo{
+				{Name: "Adult", Kind: rules.RuleCompare, Constraints: map[string]any{"field": "age", "operator": ">=", "value": 18}},
+				{Name: "AnyIncome", Kind: rules.RuleCompare, Constraints: map[string]any{"field": "income", "operator": ">", "value": 0}},
+			}},
+			{Name: "NotExcluded", Kind: rules.GroupNoneOf, Rules: []models.RuleInfo{
+				{Name: "Minor", Kind: rules.RuleCompare, Constraints: map[string]any{"field": "age", "operator": "<", "value": 16}},
+				{Name: "Retired", Kind: rules.RuleCompare, Constraints: map[string]any{"field": "age", "operator": ">", "value": 100}},
+			}},
+		},
+	}, nil)
+
+	engine, err := rules.NewRulesEngine(fileManager)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	applicants := []models.Applicant{
+		{Income: 120000, Age: 30, PoliticallyExposed: &PPE},
+		{Income: 120000, Age: 10, PoliticallyExposed: &PPE},
+	}
+	_, report := Run(context.Background(), engine, applicants, rules.WithMode(rules.ModeEvaluateAll))
+
+	assert.Equal(t, []string{rules.RuleIncome, "Eligible", "Adult", "AnyIncome", "NotExcluded", "Minor", "Retired"}, report.Rules)
+	assert.Equal(t, map[string]int{"Eligible": 1, "Adult": 1, "NotExcluded": 1, "Minor": 1}, report.RuleFailures)
+	assert.Equal(t, []string{rules.RuleIncome, "AnyIncome", "Retired"}, report.NeverFired)
+}
+
+func Test_Diff(t *testing.T) {
+	PPE := false
+	newEngine := func(minSalary, minAge int) *rules.RulesEngine {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+			RequiredRules: []string{},
+			Rules: []models.RuleInfo{
+				{Name: rules.RuleIncome, Constraints: map[string]any{"minimum_salary": minSalary}},
+				{Name: rules.RuleAge, Constraints: map[string]any{"min_age_allowed": minAge}},
+			},
+		}, nil)
+		engine, err := rules.NewRulesEngine(fileManager)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return engine
+	}
+
+	applicants := []models.Applicant{
+		{Income: 120000, Age: 30, PoliticallyExposed: &PPE},
+		{Income: 160000, Age: 30, PoliticallyExposed: &PPE},
+		{Income: 120000, Age: 19, PoliticallyExposed: &PPE},
+		{Income: 90000, Age: 19},
+	}
+
+	report := Diff(context.Background(), newEngine(100000, 18), newEngine(150000, 20), applicants, rules.WithMode(rules.ModeEvaluateAll))
+
+	assert.Equal(t, 4, report.Records)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, 1, report.Unchanged)
+	assert.Equal(t, map[string]int{"approved -> declined": 2}, report.Transitions)
+	assert.Equal(t, map[string]int{rules.RuleIncome: 2, rules.RuleAge: 1}, report.RuleFlips)
+	assert.Equal(t, []Flip{
+		{Index: 0, Before: rules.StatusApproved, After: rules.StatusDeclined, Rules: []string{rules.RuleIncome}},
+		{Index: 2, Before: rules.StatusApproved, After: rules.StatusDeclined, Rules: []string{rules.RuleIncome, rules.RuleAge}},
+	}, report.Flips)
+
+	reverse := Diff(context.Background(), newEngine(150000, 20), newEngine(100000, 18), applicants, rules.WithMode(rules.ModeEvaluateAll))
+	assert.Equal(t, map[string]int{"declined -> approved": 2}, reverse.Transitions)
+	assert.Equal(t, []string{rules.RuleIncome, rules.RuleAge}, reverse.Flips[1].Rules)
+}
+
+func Test_RenamedMasterRule(t *testing.T) {
+	PPE := false
+	newEngine := func(approvedPhones ...helpers.ApprovedPhone) *rules.RulesEngine {
+		fileManager := mocks.NewFileManager(t)
+		fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+			RequiredRules: []string{},
+			Rules: []models.RuleInfo{
+				{Name: "Bypass", Kind: rules.RuleMaster, Constraints: map[string]any{"check_approved_phones": true}},
+				{Name: rules.RuleIncome, Constraints: map[string]any{"minimum_salary": 100000}},
+			},
+		}, nil)
+		engine, err := rules.NewRulesEngine(fileManager, rules.WithApprovedPhones(helpers.NewMemoryApprovedPhoneStore(approvedPhones...)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return engine
+	}
+
+	applicants := []models.Applicant{
+		{Income: 90000, Age: 30, PoliticallyExposed: &PPE, PhoneNumber: "269-741-8863"},
+		{Income: 90000, Age: 30, PoliticallyExposed: &PPE, PhoneNumber: "486-356-0375"},
+	}
+	before, after := newEngine(), newEngine(helpers.ApprovedPhone{Phone: "269-741-8863"})
+
+	_, report := Run(context.Background(), after, applicants, rules.WithMode(rules.ModeEvaluateAll))
+	assert.Equal(t, "Bypass", report.BypassRule)
+	assert.Equal(t, 1, report.Bypassed)
+	assert.Equal(t, []string{}, report.NeverFired)
+
+	diff := Diff(context.Background(), before, after, applicants, rules.WithMode(rules.ModeEvaluateAll))
+	assert.Equal(t, []Flip{
+		{Index: 0, Before: rules.StatusDeclined, After: rules.StatusApproved, Rules: []string{"Bypass", rules.RuleIncome}},
+	}, diff.Flips)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ilivestrong/rules-engine/rules"
+	"github.com/ilivestrong/rules-engine/stream"
+)
+
+// streamDecisions evaluates newline-delimited applicants from --input (default stdin) and writes one decision per
+// line to --output (default stdout) as they are made, with a summary on stderr.
+func streamDecisions(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("stream", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	rulesPath := flags.String("rules", "rules/rules.json", "rules config to evaluate")
+	approvedPhones := flags.String("approved-phones", "", "approved phone list (default: approved-phone-list.json next to --rules)")
+	inputPath := flags.String("input", "", "newline-delimited applicants (default: stdin)")
+	outputPath := flags.String("output", "", "file for the decisions (default: stdout)")
+	mode := flags.String("mode", rules.ModeShortCircuit, "evaluation mode")
+	workers := flags.Int("workers", 0, "number of parallel workers (default: number of CPUs)")
+	explain := flags.Bool("explain", false, "include the per-rule breakdown of every decision")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !rules.ValidMode(*mode) {
+		fmt.Fprintf(stderr, "invalid mode %q\n", *mode)
+		return 2
+	}
+
+	engine, err := offlineEngine(*rulesPath, *approvedPhones)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	in := stdin
+	if *inputPath != "" {
+		file, err := os.Open(*inputPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to open input: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		in = file
+	}
+	out := stdout
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to create output: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	summary, err := stream.Process(context.Background(), engine, in, out, stream.Options{
+		Workers:       *workers,
+		Explain:       *explain,
+		VerifyOptions: []rules.VerifyOption{rules.WithMode(*mode)},
+	})
+	fmt.Fprintf(stderr, "records %d, invalid %d, statuses %v\n", summary.Records, summary.Invalid, summary.Statuses)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,202 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/ilivestrong/rules-engine/models"
+	"github.com/ilivestrong/rules-engine/rules"
+)
+
+const (
+	// StatusInvalid is the status of lines that could not be evaluated, see the result's error.
+	StatusInvalid = "invalid"
+
+	DefaultMaxLineBytes = 1 << 20
+)
+
+type (
+	Options struct {
+		Workers       int
+		Explain       bool
+		VerifyOptions []rules.VerifyOption
+		MaxLineBytes  int
+	}
+
+	Result struct {
+		Line         int             `json:"line"`
+		Status       string          `json:"status"`
+		RulesVersion string          `json:"rules_version,omitempty"`
+		Error        string          `json:"error,omitempty"`
+		Decision     *rules.Decision `json:"decision,omitempty"`
+	}
+
+	Summary struct {
+		Records  int            `json:"records"`
+		Invalid  int            `json:"invalid"`
+		Statuses map[string]int `json:"statuses"`
+	}
+
+	job struct {
+		line   int
+		data   []byte
+		result chan Result
+	}
+
+	// LineError stops a stream at a line that can't be read, e.g. one longer than MaxLineBytes.
+	LineError struct {
+		Line int
+		Err  error
+	}
+
+	// flusher is implemented by http.ResponseWriter, each result is pushed to the client as soon as it is made.
+	flusher interface {
+		Flush()
+	}
+)
+
+// Process reads newline-delimited applicants from r and writes one decision per line to w, in input order.
+// Lines are evaluated by a pool of workers and at most a few lines per worker are in flight, so a slow writer
+// slows down reading instead of piling up results. Blank lines are skipped, malformed ones get an invalid result.
+// A w that can be flushed, such as an http.ResponseWriter, gets every decision as soon as it is made, anything
+// else stays buffered until the end.
+func Process(ctx context.Context, engine *rules.RulesEngine, r io.Reader, w io.Writer, opts Options) (*Summary, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	maxLineBytes := opts.MaxLineBytes
+	if maxLineBytes <= 0 {
+		maxLineBytes = DefaultMaxLineBytes
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan job)
+	pending := make(chan chan Result, workers*4)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.result <- evaluate(ctx, engine, j, opts)
+			}
+		}()
+	}
+
+	readErr := make(chan *LineError, 1)
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+
+		initial := 64 * 1024
+		if initial > maxLineBytes {
+			initial = maxLineBytes
+		}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, initial), maxLineBytes)
+		line := 0
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			j := job{line: line, data: append([]byte(nil), data...), result: make(chan Result, 1)}
+			select {
+			case pending <- j.result:
+			case <-ctx.Done():
+				readErr <- &LineError{Line: line, Err: ctx.Err()}
+				return
+			}
+			jobs <- j
+		}
+		if err := scanner.Err(); err != nil {
+			readErr <- &LineError{Line: line + 1, Err: err}
+			return
+		}
+		readErr <- nil
+	}()
+
+	summary := &Summary{Statuses: make(map[string]int)}
+	_, live := w.(flusher)
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	for resultCh := range pending {
+		res := <-resultCh
+		summary.Records++
+		if res.Status == StatusInvalid {
+			summary.Invalid++
+		} else {
+			summary.Statuses[res.Status]++
+		}
+
+		if err := encoder.Encode(res); err != nil {
+			return summary, fmt.Errorf("failed to write decision for line %d: %v", res.Line, err)
+		}
+		if !live {
+			continue
+		}
+		if err := flush(out, w); err != nil {
+			return summary, err
+		}
+	}
+	wg.Wait()
+
+	// a line that can't be read ends the stream, the client still learns where it stopped
+	if lineErr := <-readErr; lineErr != nil {
+		encoder.Encode(Result{Line: lineErr.Line, Status: StatusInvalid, Error: lineErr.Err.Error()})
+		flush(out, w)
+		return summary, lineErr
+	}
+	return summary, flush(out, w)
+}
+
+func (le *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", le.Line, le.Err)
+}
+
+func (le *LineError) Unwrap() error {
+	return le.Err
+}
+
+func evaluate(ctx context.Context, engine *rules.RulesEngine, j job, opts Options) Result {
+	res := Result{Line: j.line, Status: StatusInvalid}
+
+	var applicant models.Applicant
+	if err := json.Unmarshal(j.data, &applicant); err != nil {
+		res.Error = fmt.Sprintf("invalid applicant: %v", err)
+		return res
+	}
+	if err := applicant.Validate(); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	decision := engine.Verify(ctx, &applicant, opts.VerifyOptions...)
+	res.Status = decision.Status
+	res.RulesVersion = decision.RulesVersion
+	if opts.Explain {
+		res.Decision = decision
+	}
+	return res
+}
+
+func flush(out *bufio.Writer, w io.Writer) error {
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("failed to write decisions: %v", err)
+	}
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ilivestrong/rules-engine/helpers/mocks"
+	"github.com/ilivestrong/rules-engine/models"
+	"github.com/ilivestrong/rules-engine/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEngine(t *testing.T) *rules.RulesEngine {
+	fileManager := mocks.NewFileManager(t)
+	fileManager.On("LoadRulesFromConfig").Return(&models.RulesConfig{
+		RequiredRules: []string{},
+		Rules: []models.RuleInfo{
+			{Name: rules.RuleMaster, Constraints: map[string]any{"check_approved_phones": true}},
+			{Name: rules.RuleAge, Constraints: map[string]any{"min_age_allowed": 18}},
+		},
+	}, nil)
+
+	engine, err := rules.NewRulesEngine(fileManager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
+
+func readResults(t *testing.T, out *bytes.Buffer) []Result {
+	var results []Result
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var res Result
+		if err := json.Unmarshal(scanner.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+type (
+	countingWriter struct {
+		bytes.Buffer
+		writes int
+	}
+
+	flushingWriter struct {
+		countingWriter
+		flushes int
+	}
+)
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.writes++
+	return cw.Buffer.Write(p)
+}
+
+func (fw *flushingWriter) Flush() {
+	fw.flushes++
+}
+
+func Test_Process(t *testing.T) {
+	engine := newEngine(t)
+	version := engine.Version().ID
+
+	t.Run("decisions keep input order", func(t *testing.T) {
+		var in strings.Builder
+		for i := 0; i < 500; i++ {
+			fmt.Fprintf(&in, `{"age": %d, "politically_exposed": false}`+"\n", i%30)
+		}
+		var out bytes.Buffer
+
+		summary, err := Process(context.Background(), engine, strings.NewReader(in.String()), &out, Options{Workers: 8})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 500, summary.Records)
+		results := readResults(t, &out)
+		if assert.Len(t, results, 500) {
+			for i, res := range results {
+				expected := rules.StatusDeclined
+				if i%30 >= 18 {
+					expected = rules.StatusApproved
+				}
+				assert.Equal(t, Result{Line: i + 1, Status: expected, RulesVersion: version}, res)
+			}
+		}
+	})
+
+	t.Run("malformed lines are tagged with their line number", func(t *testing.T) {
+		in := `{"age": 30, "politically_exposed": false}
+
+{"age": 30
+{"age": 30}
+`
+		var out bytes.Buffer
+
+		summary, err := Process(context.Background(), engine, strings.NewReader(in), &out, Options{Workers: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &Summary{Records: 3, Invalid: 2, Statuses: map[string]int{rules.StatusApproved: 1}}, summary)
+		assert.Equal(t, []Result{
+			{Line: 1, Status: rules.StatusApproved, RulesVersion: version},
+			{Line: 3, Status: StatusInvalid, Error: "invalid applicant: unexpected end of JSON input"},
+			{Line: 4, Status: StatusInvalid, Error: "politically_exposed is required"},
+		}, readResults(t, &out))
+	})
+
+	t.Run("a line over the limit ends the stream", func(t *testing.T) {
+		in := `{"age": 30, "politically_exposed": false}` + "\n" + `{"job_industry_code": "` + strings.Repeat("x", 200) + `"}` + "\n"
+		var out bytes.Buffer
+
+		_, err := Process(context.Background(), engine, strings.NewReader(in), &out, Options{MaxLineBytes: 100})
+
+		assert.EqualError(t, err, "line 2: bufio.Scanner: token too long")
+		assert.Equal(t, []Result{
+			{Line: 1, Status: rules.StatusApproved, RulesVersion: version},
+			{Line: 2, Status: StatusInvalid, Error: "bufio.Scanner: token too long"},
+		}, readResults(t, &out))
+	})
+	t.Run("only writers that can be flushed get each decision as it is made", func(t *testing.T) {
+		in := strings.Repeat(`{"age": 30, "politically_exposed": false}`+"\n", 10)
+		var file countingWriter
+		var client flushingWriter
+
+		_, err := Process(context.Background(), engine, strings.NewReader(in), &file, Options{Workers: 2})
+		assert.NoError(t, err)
+		_, err = Process(context.Background(), engine, strings.NewReader(in), &client, Options{Workers: 2})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, file.writes)
+		assert.Len(t, readResults(t, &file.Buffer), 10)
+		assert.Equal(t, 11, client.flushes)
+		assert.Len(t, readResults(t, &client.Buffer), 10)
+	})
+}